@@ -20,6 +20,8 @@ import (
 	"talents/db"
 	"talents/llm"
 	"talents/pdf"
+	"talents/skill"
+	"talents/university"
 	"talents/utils"
 	"talents/utils/jwt"
 
@@ -41,14 +43,68 @@ func Router() *gin.Engine {
 	r.PUT("/talent/:id", updateTalent)
 	r.DELETE("/talent/:id", deleteTalent)
 	r.GET("/talents", searchTalents)
+	r.GET("/trash/talents", listTrash)
+	r.POST("/trash/talents/:id/restore", restoreTalent)
+	r.DELETE("/trash/talents/:id", purgeTalent)
 	r.POST("/talent/upload-resume", uploadResumeAndCreateTalent)
 	r.POST("/talent/upload-resumes", uploadMultipleResumesAndCreateTalents)
 	r.POST("/talents/recalculate-scores", recalculateScores)
+	r.POST("/talents/recalculate-scores/apply", applyRecalculation)
 	r.POST("/talent/:id/interview-record", updateInterviewRecord)
+	r.GET("/talent/:id/interviews", listInterviews)
+	r.POST("/talent/:id/interviews", createInterview)
+	r.GET("/talent/:id/interviews/:interviewId", getInterview)
+	r.PUT("/talent/:id/interviews/:interviewId", updateInterview)
+	r.DELETE("/talent/:id/interviews/:interviewId", deleteInterview)
+	r.POST("/talent/:id/stage", moveTalentStage)
+	r.GET("/talent/:id/stage-history", listStageTransitions)
+	r.GET("/pipeline", getPipeline)
+	r.GET("/pipeline/stages", listStages)
+	r.GET("/talent/:id/comments", listComments)
+	r.POST("/talent/:id/comments", createComment)
+	r.PUT("/talent/:id/comments/:commentId", updateComment)
+	r.DELETE("/talent/:id/comments/:commentId", deleteComment)
+	r.PUT("/talent/:id/tags", setTalentTags)
+	r.PUT("/talent/:id/owner", setTalentOwner)
+	r.GET("/tags", listTags)
+	r.GET("/admin/audit-logs", listAuditLogs)
 	r.POST("/talent/:id/reparse-resume", reparseResume)
 	r.POST("/talent/:id/generate-interview-questions", generateInterviewQuestions)
+	r.GET("/talent/:id/score-explanation", getScoreExplanation)
+	r.GET("/talent/:id/score-history", getScoreHistory)
 	r.Static("/resumes", "./resumes")
 	r.GET("/resume/:phone", getResumeByPhone)
+	r.GET("/talent/:id/resume", getResume)
+	r.GET("/talent/:id/resumes", listResumes)
+	r.POST("/talent/:id/resumes", uploadResumeVersion)
+	r.GET("/talent/:id/resumes/:resumeId", getResumeVersion)
+	r.POST("/talent/:id/resumes/:resumeId/activate", activateResumeVersion)
+	r.GET("/admin/scoring-rules", getScoringRules)
+	r.POST("/admin/scoring-rules/reload", reloadScoringRules)
+	r.GET("/admin/universities/versions", listUniversityRankings)
+	r.POST("/admin/universities/versions", uploadUniversityRanking)
+	r.POST("/admin/universities/versions/:version/activate", activateUniversityRanking)
+	r.GET("/weights", listWeights)
+	r.GET("/companies", listCompanies)
+	r.POST("/companies", createCompany)
+	r.PUT("/companies/:id", updateCompany)
+	r.DELETE("/companies/:id", deleteCompany)
+	r.GET("/companies/resolve", resolveCompany)
+	r.GET("/skills", listSkills)
+	r.GET("/universities", listUniversities)
+	r.GET("/universities/unresolved", listUnresolvedUniversities)
+	r.GET("/jobs", listJobs)
+	r.POST("/jobs", createJob)
+	r.GET("/jobs/:id", getJob)
+	r.PUT("/jobs/:id", updateJob)
+	r.DELETE("/jobs/:id", deleteJob)
+	r.GET("/jobs/:id/talents", listJobTalents)
+	r.GET("/jobs/:id/candidates", listJobCandidates)
+	r.GET("/talent/:id/jobs", listTalentJobs)
+	r.POST("/talent/:id/jobs", linkTalentJob)
+	r.DELETE("/talent/:id/jobs/:jobId", unlinkTalentJob)
+	r.PUT("/weights/:position", updateWeights)
+	r.DELETE("/weights/:position", deleteWeights)
 
 	// Serve static files for the frontend from embedded filesystem
 	staticFS, err := fs.Sub(staticFiles, "static")
@@ -104,6 +160,17 @@ func AuthRequired() gin.HandlerFunc {
 	}
 }
 
+// getUID returns the uid set by AuthRequired
+func getUID(c *gin.Context) uint {
+	switch uid := c.MustGet("uid").(type) {
+	case uint:
+		return uid
+	case int:
+		return uint(uid)
+	}
+	return 0
+}
+
 func createTalent(c *gin.Context) {
 	var talent db.Talent
 	if err := c.ShouldBindJSON(&talent); err != nil {
@@ -111,7 +178,12 @@ func createTalent(c *gin.Context) {
 		return
 	}
 
-	if err := db.CreateTalent(&talent); err != nil {
+	talent.NormalizeEmployments()
+	talent.NormalizeEducations()
+	talent.Skills = skill.Normalize(talent.Skills)
+	talent.Companies = db.NormalizeCompanies(talent.Companies)
+	talent.Tags = db.NormalizeTags(talent.Tags)
+	if err := db.CreateTalent(&talent, auditActor(c)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -132,13 +204,22 @@ func getTalent(c *gin.Context) {
 
 func updateTalent(c *gin.Context) {
 	id := c.Param("id")
+	if _, err := db.GetTalent(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
 	var talent db.Talent
 	if err := c.ShouldBindJSON(&talent); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := db.UpdateTalent(id, &talent); err != nil {
+	talent.NormalizeEmployments()
+	talent.NormalizeEducations()
+	talent.Skills = skill.Normalize(talent.Skills)
+	talent.Companies = db.NormalizeCompanies(talent.Companies)
+	// 请求中带有工作经历、教育经历时整体替换
+	if err := db.UpdateTalent(id, &talent, auditActor(c)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -148,22 +229,45 @@ func updateTalent(c *gin.Context) {
 
 func deleteTalent(c *gin.Context) {
 	id := c.Param("id")
-	if err := db.DeleteTalent(id); err != nil {
+	if _, err := db.GetTalent(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err := db.DeleteTalent(id, auditActor(c)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Talent deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "Talent moved to trash"})
 }
 
 func searchTalents(c *gin.Context) {
-	query := c.Query("query")
-	talents, err := db.SearchTalents(query)
+	filter, err := talentFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tier, csRating, err := universityConditions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	talents, err := db.SearchTalents(filter)
 	fmt.Println(talents)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	// 按院校层次、计算机学科评估筛选
+	if tier != "" || csRating != "" {
+		filtered := talents[:0]
+		for _, t := range talents {
+			if _, ok := university.Qualifies(t.Universities, tier, csRating); ok {
+				filtered = append(filtered, t)
+			}
+		}
+		talents = filtered
+	}
 	sort.Slice(talents, func(i, j int) bool {
 		return talents[i].AverageScore > talents[j].AverageScore
 	})
@@ -171,6 +275,31 @@ func searchTalents(c *gin.Context) {
 	c.JSON(http.StatusOK, talents)
 }
 
+// talentFilter parses the talent search conditions: query, stage, tag (repeatable, all must
+// match), owner (0 for unassigned), comment (text contained in a comment) and commenter
+func talentFilter(c *gin.Context) (db.TalentFilter, error) {
+	f := db.TalentFilter{
+		Query:   c.Query("query"),
+		Stage:   c.Query("stage"),
+		Tags:    db.NormalizeTags(c.QueryArray("tag")),
+		Comment: c.Query("comment"),
+	}
+	if f.Stage != "" && !db.ValidStage(f.Stage) {
+		return f, fmt.Errorf("invalid stage %s", f.Stage)
+	}
+	for name, target := range map[string]**uint{"owner": &f.OwnerID, "commenter": &f.CommentedBy} {
+		if v := c.Query(name); v != "" {
+			uid, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				return f, fmt.Errorf("invalid %s %s", name, v)
+			}
+			id := uint(uid)
+			*target = &id
+		}
+	}
+	return f, nil
+}
+
 func uploadResumeAndCreateTalent(c *gin.Context) {
 	file, header, err := c.Request.FormFile("resume")
 	if err != nil {
@@ -184,6 +313,12 @@ func uploadResumeAndCreateTalent(c *gin.Context) {
 		return
 	}
 
+	job, err := formJob(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "招聘需求未找到", "details": err.Error()})
+		return
+	}
+
 	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
 	filename := timestamp + "_" + header.Filename
 	resumePath := filepath.Join("resumes", filename)
@@ -218,6 +353,11 @@ func uploadResumeAndCreateTalent(c *gin.Context) {
 	existingTalent, err := db.GetTalentByHash(fileHash)
 	if err == nil {
 		// Talent with same hash already exists
+		if job != nil {
+			if err := db.LinkTalentJob(existingTalent.ID, job.ID); err != nil {
+				fmt.Printf("Error linking talent to job: %v\n", err)
+			}
+		}
 		c.JSON(http.StatusOK, gin.H{
 			"message":         "档案已存在",
 			"total":           1,
@@ -238,21 +378,24 @@ func uploadResumeAndCreateTalent(c *gin.Context) {
 		return
 	}
 
-	talent, err := pdf.GenerateTalentFromPDF(resumePath)
+	uid := getUID(c)
+	talent, resume, err := parseResumeFile(header.Filename, resumePath, fileHash, uid)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse resume: " + err.Error()})
 		return
 	}
 
-	// Save the resume path and hash in the talent object
-	talent.ResumePath = resumePath
-	talent.Hash = fileHash
-
-	// Save the talent to the database
-	if err := db.CreateTalent(talent); err != nil {
+	// Save the talent, or a new resume version of the talent with the same phone
+	existing, err := saveParsedResume(c, talent, resume)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save talent: " + err.Error()})
 		return
 	}
+	if job != nil {
+		if err := db.LinkTalentJob(talent.ID, job.ID); err != nil {
+			fmt.Printf("Error linking talent to job: %v\n", err)
+		}
+	}
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message":         "Resume processed successfully",
@@ -265,14 +408,24 @@ func uploadResumeAndCreateTalent(c *gin.Context) {
 				"filename": header.Filename,
 				"talent":   talent,
 				"file":     resumePath,
+				"existing": existing,
 			},
 		},
 	})
 }
 
+// getResumeByPhone keeps the legacy phone based resume link working
 func getResumeByPhone(c *gin.Context) {
-	phone := c.Param("phone")
-	talent, err := db.GetTalent(phone)
+	talent, err := db.GetTalentByPhone(c.Param("phone"))
+	redirectResume(c, talent, err)
+}
+
+func getResume(c *gin.Context) {
+	talent, err := db.GetTalent(c.Param("id"))
+	redirectResume(c, talent, err)
+}
+
+func redirectResume(c *gin.Context, talent *db.Talent, err error) {
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Talent not found"})
 		return
@@ -299,6 +452,12 @@ func uploadMultipleResumesAndCreateTalents(c *gin.Context) {
 		return
 	}
 
+	job, err := formJob(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "招聘需求未找到", "details": err.Error()})
+		return
+	}
+
 	// Use a mutex to protect concurrent access to results and errors slices
 	var mutex sync.Mutex
 	results := make([]gin.H, 0, len(files))
@@ -310,6 +469,8 @@ func uploadMultipleResumesAndCreateTalents(c *gin.Context) {
 		return
 	}
 
+	uid := getUID(c)
+
 	// Use a wait group to wait for all goroutines to complete
 	var wg sync.WaitGroup
 
@@ -393,6 +554,11 @@ func uploadMultipleResumesAndCreateTalents(c *gin.Context) {
 			existingTalent, err := db.GetTalentByHash(fileHash)
 			if err == nil {
 				// Talent with same hash already exists
+				if job != nil {
+					if err := db.LinkTalentJob(existingTalent.ID, job.ID); err != nil {
+						fmt.Printf("Error linking talent to job: %v\n", err)
+					}
+				}
 				mutex.Lock()
 				duplicates = append(duplicates, gin.H{
 					"filename":        fileHeader.Filename,
@@ -407,7 +573,7 @@ func uploadMultipleResumesAndCreateTalents(c *gin.Context) {
 			}
 
 			// Generate talent from PDF
-			talent, err := pdf.GenerateTalentFromPDF(resumePath)
+			talent, resume, err := parseResumeFile(fileHeader.Filename, resumePath, fileHash, uid)
 			if err != nil {
 				mutex.Lock()
 				errors = append(errors, gin.H{
@@ -418,12 +584,9 @@ func uploadMultipleResumesAndCreateTalents(c *gin.Context) {
 				return
 			}
 
-			// Save the resume path and hash in the talent object
-			talent.ResumePath = resumePath
-			talent.Hash = fileHash
-
-			// Save the talent to the database
-			if err := db.CreateTalent(talent); err != nil {
+			// Save the talent, or a new resume version of the talent with the same phone
+			existing, err := saveParsedResume(c, talent, resume)
+			if err != nil {
 				mutex.Lock()
 				errors = append(errors, gin.H{
 					"filename": fileHeader.Filename,
@@ -432,6 +595,11 @@ func uploadMultipleResumesAndCreateTalents(c *gin.Context) {
 				mutex.Unlock()
 				return
 			}
+			if job != nil {
+				if err := db.LinkTalentJob(talent.ID, job.ID); err != nil {
+					fmt.Printf("Error linking talent to job: %v\n", err)
+				}
+			}
 
 			// Add success result
 			mutex.Lock()
@@ -439,6 +607,7 @@ func uploadMultipleResumesAndCreateTalents(c *gin.Context) {
 				"filename": fileHeader.Filename,
 				"talent":   talent,
 				"file":     resumePath,
+				"existing": existing,
 			})
 			mutex.Unlock()
 		}(fileHeader)
@@ -459,10 +628,17 @@ func uploadMultipleResumesAndCreateTalents(c *gin.Context) {
 	})
 }
 
-// recalculateScores updates scores for all talents based on current scoring logic
+// recalculateScores updates scores for all talents based on current scoring logic.
+// With dry_run=true nothing is written; the returned preview_id can be passed to
+// applyRecalculation to commit exactly the previewed scores.
 func recalculateScores(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+
 	// Recalculate scores for all talents
-	result, err := db.RecalculateAllTalentScores()
+	result, err := db.PreviewRecalculation()
+	if err == nil && !dryRun {
+		err = db.ApplyRecalculation(result, db.TriggerRecalc, auditActor(c))
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   err.Error(),
@@ -471,7 +647,16 @@ func recalculateScores(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	resp := recalculationResponse(result)
+	if dryRun {
+		resp["message"] = "分数重新计算预览"
+		resp["preview_id"] = savePreview(result)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func recalculationResponse(result *db.RecalculationResult) gin.H {
+	return gin.H{
 		"message":         "分数重新计算完成",
 		"total_count":     result.TotalCount,
 		"updated_count":   result.UpdatedCount,
@@ -480,10 +665,25 @@ func recalculateScores(c *gin.Context) {
 		"maximum_change":  result.MaximumChange,
 		"score_changes":   result.ScoreChanges,
 		"maximum_talent":  result.MaximumTalent,
+		"rule_version":    result.RuleVersion,
+		"positions":       result.Positions,
+		"top_movers":      result.TopMovers,
+		"applied":         result.Applied,
+	}
+}
+
+// listSkills returns the skill taxonomy used to normalise and match skills
+func listSkills(c *gin.Context) {
+	skills := skill.All()
+	sort.Slice(skills, func(i, j int) bool {
+		return skills[i].Name < skills[j].Name
 	})
+
+	c.JSON(http.StatusOK, skills)
 }
 
-// updateInterviewRecord handles updating a talent's interview record
+// updateInterviewRecord keeps the legacy single interview record working by saving it as the
+// notes of the talent's first interview round
 func updateInterviewRecord(c *gin.Context) {
 	id := c.Param("id")
 	fmt.Printf("Updating interview record for talent ID: %s\n", id)
@@ -513,27 +713,16 @@ func updateInterviewRecord(c *gin.Context) {
 		return
 	}
 
-	// Update only the interview record field
-	talent.InterviewRecord = requestBody.InterviewRecord
-
-	// Try direct SQL update if GORM model update fails
-	if err := db.UpdateTalent(id, talent); err != nil {
-		fmt.Printf("Error updating talent with GORM: %v\n", err)
-
-		// Try direct SQL as fallback (useful if column was just added)
-		if err := db.UpdateTalentInterviewRecord(id, requestBody.InterviewRecord); err != nil {
-			fmt.Printf("Error updating with direct SQL: %v\n", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "更新面试记录失败",
-				"details": "请确保数据库已更新，包含interview_record字段: " + err.Error(),
-			})
-			return
-		}
+	interview, err := db.SaveFirstInterviewNotes(talent.ID, requestBody.InterviewRecord, getUID(c))
+	if err != nil {
+		fmt.Printf("Error saving interview record: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新面试记录失败", "details": err.Error()})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "面试记录已更新",
-		"talent":  talent,
+		"message":   "面试记录已更新",
+		"interview": interview,
 	})
 }
 
@@ -562,21 +751,25 @@ func reparseResume(c *gin.Context) {
 	}
 
 	// Re-parse the resume
-	newTalent, err := pdf.GenerateTalentFromPDF(talent.ResumePath)
+	newTalent, text, err := pdf.ParseResume(talent.ResumePath)
 	if err != nil {
 		fmt.Printf("Error re-parsing resume: %v\n", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "重新解析简历失败", "details": err.Error()})
 		return
 	}
 
-	// Preserve the original Phone, ResumePath, Hash, and InterviewRecord
-	newTalent.Phone = talent.Phone
-	newTalent.ResumePath = talent.ResumePath
-	newTalent.Hash = talent.Hash
-	newTalent.InterviewRecord = talent.InterviewRecord
+	// Keep the parse result of the active resume version up to date
+	if resume, err := db.GetResume(talent.ID, strconv.FormatUint(uint64(talent.ResumeID), 10)); err == nil {
+		resume.Text = text
+		if err := resume.SetParsed(newTalent); err == nil {
+			if err := db.UpdateResumeParse(resume); err != nil {
+				fmt.Printf("Error saving resume parse result: %v\n", err)
+			}
+		}
+	}
 
-	// Update the talent in the database
-	if err := db.UpdateTalent(id, newTalent); err != nil {
+	// Update the talent in the database, preserving its ID, Phone and resume
+	if err := db.ApplyProfile(talent, newTalent, nil, db.TriggerReparse, auditActor(c)); err != nil {
 		fmt.Printf("Error updating talent: %v\n", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新人才信息失败", "details": err.Error()})
 		return
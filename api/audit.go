@@ -0,0 +1,89 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"talents/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// auditEndpoint returns the method and route of the request, e.g. PUT /talent/:id
+func auditEndpoint(c *gin.Context) string {
+	path := c.FullPath()
+	if path == "" {
+		path = c.Request.URL.Path
+	}
+	return c.Request.Method + " " + path
+}
+
+// auditActor returns the current user and route, recorded with the changes the request makes
+func auditActor(c *gin.Context) db.Actor {
+	return db.Actor{UserID: getUID(c), Endpoint: auditEndpoint(c)}
+}
+
+// parseAuditTime accepts RFC 3339 times and dates such as 2025-01-31
+func parseAuditTime(s string) (*time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			t = t.Local()
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid time %s", s)
+}
+
+// listAuditLogs queries the audit log by actor, talent and time range [since, until),
+// with limit (default 100) and offset paging
+func listAuditLogs(c *gin.Context) {
+	f := db.AuditFilter{Limit: 100}
+	if v := c.Query("actor"); v != "" {
+		uid, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid actor " + v})
+			return
+		}
+		actor := uint(uid)
+		f.UserID = &actor
+	}
+	if v := c.Query("talent"); v != "" {
+		talentID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid talent " + v})
+			return
+		}
+		f.TalentID = &talentID
+	}
+	for name, target := range map[string]**time.Time{"since": &f.Since, "until": &f.Until} {
+		if v := c.Query(name); v != "" {
+			t, err := parseAuditTime(v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			*target = t
+		}
+	}
+	for name, target := range map[string]*int{"limit": &f.Limit, "offset": &f.Offset} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name + " " + v})
+				return
+			}
+			*target = n
+		}
+	}
+	f.Limit = min(max(f.Limit, 1), 1000)
+
+	logs, total, err := db.ListAuditLogs(f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": total, "logs": logs})
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"talents/db"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func listComments(c *gin.Context) {
+	talent, ok := routeTalent(c)
+	if !ok {
+		return
+	}
+	comments, err := db.ListComments(talent.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// createComment adds a comment by the current user, with parentId it replies to another comment
+func createComment(c *gin.Context) {
+	talent, ok := routeTalent(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Body     string `json:"body"`
+		ParentID *uint  `json:"parentId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment := &db.Comment{TalentID: talent.ID, ParentID: req.ParentID, AuthorID: getUID(c), Body: req.Body}
+	if err := db.CreateComment(comment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+func updateComment(c *gin.Context) {
+	talent, ok := routeTalent(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := db.UpdateComment(talent.ID, c.Param("commentId"), getUID(c), req.Body)
+	if err != nil {
+		c.JSON(commentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+func deleteComment(c *gin.Context) {
+	talent, ok := routeTalent(c)
+	if !ok {
+		return
+	}
+	if err := db.DeleteComment(talent.ID, c.Param("commentId"), getUID(c)); err != nil {
+		c.JSON(commentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+func commentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrNotCommentAuthor):
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
+
+// setTalentTags replaces the tags of a talent
+func setTalentTags(c *gin.Context) {
+	talent, ok := routeTalent(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Tags []string `json:"tags"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tags, err := db.SetTalentTags(talent.ID, req.Tags, auditActor(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// setTalentOwner assigns the owning recruiter, the current user when ownerId is omitted
+// and nobody when it is 0
+func setTalentOwner(c *gin.Context) {
+	talent, ok := routeTalent(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		OwnerID *uint `json:"ownerId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	owner := getUID(c)
+	if req.OwnerID != nil {
+		owner = *req.OwnerID
+	}
+
+	if err := db.SetTalentOwner(talent.ID, owner, auditActor(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ownerId": owner})
+}
+
+func listTags(c *gin.Context) {
+	tags, err := db.ListTags()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"talents/db"
+	"talents/scoring"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// checkCompanyTier makes sure a company's tier exists in the active scoring rules
+func checkCompanyTier(company *db.Company) error {
+	if company.Tier == "" {
+		return nil
+	}
+	if _, ok := scoring.Active().Tier(company.Tier); !ok {
+		return fmt.Errorf("unknown tier %q", company.Tier)
+	}
+	return nil
+}
+
+func listCompanies(c *gin.Context) {
+	companies, err := db.ListCompanies()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, companies)
+}
+
+func createCompany(c *gin.Context) {
+	var company db.Company
+	if err := c.ShouldBindJSON(&company); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	company.ID = 0
+	if err := checkCompanyTier(&company); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := db.CreateCompany(&company); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, company)
+}
+
+func updateCompany(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid company id"})
+		return
+	}
+	if _, err := db.GetCompany(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	var company db.Company
+	if err := c.ShouldBindJSON(&company); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	company.ID = uint(id)
+	if err := checkCompanyTier(&company); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := db.UpdateCompany(&company); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, company)
+}
+
+func deleteCompany(c *gin.Context) {
+	if err := db.DeleteCompany(c.Param("id")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "company not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Company deleted successfully"})
+}
+
+// resolveCompany shows which registry entry a company name resolves to
+func resolveCompany(c *gin.Context) {
+	company := db.ResolveCompany(c.Query("name"))
+	if company == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "公司库中未找到该公司"})
+		return
+	}
+
+	c.JSON(http.StatusOK, company)
+}
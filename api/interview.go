@@ -0,0 +1,122 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"talents/db"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// routeTalent loads the talent of a /talent/:id route, responding 404 when it does not exist
+func routeTalent(c *gin.Context) (*db.Talent, bool) {
+	talent, err := db.GetTalent(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "人才信息未找到", "details": err.Error()})
+		return nil, false
+	}
+	return talent, true
+}
+
+func listInterviews(c *gin.Context) {
+	talent, ok := routeTalent(c)
+	if !ok {
+		return
+	}
+	interviews, err := db.ListInterviews(talent.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, interviews)
+}
+
+// createInterview adds an interview round, the interviewer is the current user. Without a
+// round number it becomes the round after the last one.
+func createInterview(c *gin.Context) {
+	talent, ok := routeTalent(c)
+	if !ok {
+		return
+	}
+	var interview db.Interview
+	if err := c.ShouldBindJSON(&interview); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	interview.ID = 0
+	interview.TalentID = talent.ID
+	interview.Interviewer = getUID(c)
+
+	if err := db.CreateInterview(&interview); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, interview)
+}
+
+func getInterview(c *gin.Context) {
+	talent, ok := routeTalent(c)
+	if !ok {
+		return
+	}
+	interview, err := db.GetInterview(talent.ID, c.Param("interviewId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, interview)
+}
+
+func updateInterview(c *gin.Context) {
+	talent, ok := routeTalent(c)
+	if !ok {
+		return
+	}
+	old, err := db.GetInterview(talent.ID, c.Param("interviewId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	var interview db.Interview
+	if err := c.ShouldBindJSON(&interview); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	interview.ID = old.ID
+	interview.TalentID = old.TalentID
+	interview.Interviewer = old.Interviewer
+	interview.CreatedAt = old.CreatedAt
+	if interview.Round == 0 {
+		interview.Round = old.Round
+	}
+
+	if err := db.UpdateInterview(&interview); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, interview)
+}
+
+func deleteInterview(c *gin.Context) {
+	talent, ok := routeTalent(c)
+	if !ok {
+		return
+	}
+	if err := db.DeleteInterview(talent.ID, c.Param("interviewId")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Interview deleted successfully"})
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"talents/db"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func listJobs(c *gin.Context) {
+	jobs, err := db.ListJobs(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+func createJob(c *gin.Context) {
+	var job db.Job
+	if err := c.ShouldBindJSON(&job); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	job.ID = 0
+
+	if err := db.CreateJob(&job); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, job)
+}
+
+func getJob(c *gin.Context) {
+	job, err := db.GetJob(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func updateJob(c *gin.Context) {
+	old, err := db.GetJob(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	var job db.Job
+	if err := c.ShouldBindJSON(&job); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	job.ID = old.ID
+	job.CreatedAt = old.CreatedAt
+
+	if err := db.UpdateJob(&job); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func deleteJob(c *gin.Context) {
+	if err := db.DeleteJob(c.Param("id")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Job deleted successfully"})
+}
+
+// listJobTalents ranks the talents linked to a job by their score against the job
+func listJobTalents(c *gin.Context) {
+	job, err := db.GetJob(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	talents, err := db.ListJobTalents(job.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	type rankedTalent struct {
+		Talent *db.Talent           `json:"talent"`
+		Score  *db.ScoreExplanation `json:"score"`
+	}
+	ranked := make([]rankedTalent, 0, len(talents))
+	for _, talent := range talents {
+		ranked = append(ranked, rankedTalent{Talent: talent, Score: talent.ExplainJobScore(job)})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score.AverageScore > ranked[j].Score.AverageScore
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"job":     job,
+		"talents": ranked,
+	})
+}
+
+func listTalentJobs(c *gin.Context) {
+	talent, err := db.GetTalent(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "人才信息未找到", "details": err.Error()})
+		return
+	}
+
+	jobs, err := db.ListTalentJobs(talent.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+func linkTalentJob(c *gin.Context) {
+	talent, err := db.GetTalent(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "人才信息未找到", "details": err.Error()})
+		return
+	}
+
+	var requestBody struct {
+		JobID uint `json:"jobId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据", "details": err.Error()})
+		return
+	}
+	job, err := db.GetJob(strconv.FormatUint(uint64(requestBody.JobID), 10))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "招聘需求未找到", "details": err.Error()})
+		return
+	}
+
+	if err := db.LinkTalentJob(talent.ID, job.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "已关联招聘需求",
+		"job":     job,
+		"score":   talent.ExplainJobScore(job),
+	})
+}
+
+func unlinkTalentJob(c *gin.Context) {
+	talent, err := db.GetTalent(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "人才信息未找到", "details": err.Error()})
+		return
+	}
+	jobID, err := strconv.ParseUint(c.Param("jobId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+
+	if err := db.UnlinkTalentJob(talent.ID, uint(jobID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已取消关联招聘需求"})
+}
+
+// formJob returns the job selected by the optional job_id form field of an upload
+func formJob(c *gin.Context) (*db.Job, error) {
+	id := c.PostForm("job_id")
+	if id == "" {
+		return nil, nil
+	}
+	return db.GetJob(id)
+}
+
+// maxCandidateLimit 候选人推荐列表一次最多返回的人数
+const maxCandidateLimit = 200
+
+// listJobCandidates returns a shortlist of the talent pool ranked by how well each talent
+// meets the job's requirements
+func listJobCandidates(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > maxCandidateLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxCandidateLimit)})
+		return
+	}
+	minScore, err := strconv.ParseFloat(c.DefaultQuery("min_score", "0"), 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid min_score"})
+		return
+	}
+	job, err := db.GetJob(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	matches, err := db.MatchCandidates(job)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	total := len(matches)
+	shortlist := make([]*db.JobMatch, 0, min(limit, total))
+	for _, m := range matches {
+		if len(shortlist) == limit || m.Score < float32(minScore) {
+			break
+		}
+		shortlist = append(shortlist, m)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"job":        job,
+		"total":      total,
+		"candidates": shortlist,
+	})
+}
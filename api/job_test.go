@@ -0,0 +1,22 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestListJobCandidatesLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, query := range []string{"limit=0", "limit=-1", "limit=abc", "limit=201", "limit=9223372036854775807", "min_score=abc"} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/job/1/candidates?"+query, nil)
+		listJobCandidates(c)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+}
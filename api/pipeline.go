@@ -0,0 +1,98 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"talents/db"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// listStages returns the pipeline stages in order with the stages each may move to
+func listStages(c *gin.Context) {
+	stages := make([]gin.H, 0, len(db.Stages))
+	for _, stage := range db.Stages {
+		stages = append(stages, gin.H{"stage": stage, "next": db.NextStages(stage)})
+	}
+
+	c.JSON(http.StatusOK, stages)
+}
+
+// getPipeline returns the number of talents in each stage, or with ?stage= the talents in that stage
+func getPipeline(c *gin.Context) {
+	if stage := c.Query("stage"); stage != "" {
+		if !db.ValidStage(stage) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stage " + stage})
+			return
+		}
+		talents, err := db.ListTalentsByStage(stage)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, talents)
+		return
+	}
+
+	counts, err := db.CountTalentsByStage()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	pipeline := make([]gin.H, 0, len(db.Stages))
+	for _, stage := range db.Stages {
+		pipeline = append(pipeline, gin.H{"stage": stage, "count": counts[stage]})
+	}
+
+	c.JSON(http.StatusOK, pipeline)
+}
+
+// moveTalentStage moves a talent to another stage, a reason is required when rejecting
+func moveTalentStage(c *gin.Context) {
+	talent, err := db.GetTalent(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "人才信息未找到", "details": err.Error()})
+		return
+	}
+
+	var req struct {
+		Stage  string `json:"stage" binding:"required"`
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transition, err := db.MoveTalentStage(talent.ID, req.Stage, req.Reason, auditActor(c))
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, db.ErrStageChanged):
+			status = http.StatusConflict
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transition)
+}
+
+func listStageTransitions(c *gin.Context) {
+	talent, err := db.GetTalent(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "人才信息未找到", "details": err.Error()})
+		return
+	}
+	transitions, err := db.ListStageTransitions(talent.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transitions)
+}
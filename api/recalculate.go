@@ -0,0 +1,87 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"talents/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// previewTTL is how long a dry-run recalculation can still be applied
+const previewTTL = 30 * time.Minute
+
+type preview struct {
+	result  *db.RecalculationResult
+	created time.Time
+}
+
+var previews = struct {
+	sync.Mutex
+	m map[string]*preview
+}{m: make(map[string]*preview)}
+
+// savePreview stores a dry-run result and returns its id, dropping expired previews
+func savePreview(result *db.RecalculationResult) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	id := hex.EncodeToString(b)
+
+	previews.Lock()
+	defer previews.Unlock()
+	for k, p := range previews.m {
+		if time.Since(p.created) > previewTTL {
+			delete(previews.m, k)
+		}
+	}
+	previews.m[id] = &preview{result: result, created: time.Now()}
+	return id
+}
+
+// takePreview removes and returns an unexpired preview
+func takePreview(id string) *db.RecalculationResult {
+	previews.Lock()
+	defer previews.Unlock()
+	p, ok := previews.m[id]
+	if !ok {
+		return nil
+	}
+	delete(previews.m, id)
+	if time.Since(p.created) > previewTTL {
+		return nil
+	}
+	return p.result
+}
+
+// applyRecalculation commits a previously previewed recalculation
+func applyRecalculation(c *gin.Context) {
+	var requestBody struct {
+		PreviewID string `json:"preview_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据", "details": err.Error()})
+		return
+	}
+
+	result := takePreview(requestBody.PreviewID)
+	if result == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "预览不存在或已过期，请重新预览"})
+		return
+	}
+
+	if err := db.ApplyRecalculation(result, db.TriggerRecalc, auditActor(c)); err != nil {
+		if errors.Is(err, db.ErrStalePreview) {
+			c.JSON(http.StatusConflict, gin.H{"error": "预览后分数已发生变化，请重新预览", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "分数重新计算失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, recalculationResponse(result))
+}
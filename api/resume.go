@@ -0,0 +1,203 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"talents/db"
+	"talents/pdf"
+	"talents/utils"
+)
+
+// saveResumeFile stores an uploaded resume under ./resumes and returns its path and hash
+func saveResumeFile(src io.Reader, name string) (string, string, error) {
+	if err := os.MkdirAll("resumes", os.ModePerm); err != nil {
+		return "", "", err
+	}
+	resumePath := filepath.Join("resumes", strconv.FormatInt(time.Now().Unix(), 10)+"_"+name)
+	out, err := os.Create(resumePath)
+	if err != nil {
+		return "", "", err
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, src); err != nil {
+		os.Remove(resumePath)
+		return "", "", err
+	}
+	hash, err := utils.CalculateFileHash(resumePath)
+	if err != nil {
+		os.Remove(resumePath)
+		return "", "", err
+	}
+	return resumePath, hash, nil
+}
+
+// parseResumeFile parses a saved resume into a profile and a resume version ready to be stored
+func parseResumeFile(name, resumePath, hash string, uid uint) (*db.Talent, *db.Resume, error) {
+	talent, text, err := pdf.ParseResume(resumePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	resume, err := db.NewResume(name, resumePath, hash, text, talent, uid)
+	if err != nil {
+		return nil, nil, err
+	}
+	return talent, resume, nil
+}
+
+// saveParsedResume stores a parsed resume. When its phone belongs to an existing talent the
+// resume becomes that talent's new active version, otherwise a new talent is created.
+func saveParsedResume(c *gin.Context, talent *db.Talent, resume *db.Resume) (existing bool, err error) {
+	if talent.Phone != "" {
+		if current, err := db.GetTalentByPhone(string(talent.Phone)); err == nil {
+			if err := db.ApplyProfile(current, talent, resume, db.TriggerUpload, auditActor(c)); err != nil {
+				return true, err
+			}
+			return true, nil
+		}
+	}
+	if talent.Phone != "" {
+		if trashed, err := db.GetTrashedTalentByPhone(string(talent.Phone)); err == nil {
+			return false, fmt.Errorf("回收站中的人才 %d 使用了相同的手机号，请先恢复", trashed.ID)
+		}
+	}
+	if err := db.CreateTalentWithResume(talent, resume, db.TriggerUpload, auditActor(c)); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// listResumes lists the resume versions of a talent, newest first
+func listResumes(c *gin.Context) {
+	talent, err := db.GetTalent(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "人才信息未找到", "details": err.Error()})
+		return
+	}
+	resumes, err := db.ListResumes(talent.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"resumeId": talent.ResumeID, "resumes": resumes})
+}
+
+// getResumeVersion returns a resume version with its extracted text and parse result
+func getResumeVersion(c *gin.Context) {
+	talent, err := db.GetTalent(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "人才信息未找到", "details": err.Error()})
+		return
+	}
+	resume, err := db.GetResume(talent.ID, c.Param("resumeId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "简历版本未找到", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resume)
+}
+
+// uploadResumeVersion adds a new resume version to an existing talent. The new version drives
+// the profile unless activate=false.
+func uploadResumeVersion(c *gin.Context) {
+	talent, err := db.GetTalent(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "人才信息未找到", "details": err.Error()})
+		return
+	}
+
+	file, header, err := c.Request.FormFile("resume")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No resume file provided"})
+		return
+	}
+	defer file.Close()
+	if filepath.Ext(header.Filename) != ".pdf" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only PDF files are supported"})
+		return
+	}
+
+	resumePath, hash, err := saveResumeFile(file, header.Filename)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save resume", "details": err.Error()})
+		return
+	}
+	if existing, err := db.GetTalentByHash(hash); err == nil {
+		os.Remove(resumePath)
+		c.JSON(http.StatusConflict, gin.H{"error": "简历已存在", "existing_talent": existing})
+		return
+	}
+
+	uid := getUID(c)
+	parsed, resume, err := parseResumeFile(header.Filename, resumePath, hash, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse resume: " + err.Error()})
+		return
+	}
+	if c.DefaultQuery("activate", "true") != "true" {
+		if err := db.AddResume(talent.ID, resume); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "保存简历失败", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"message": "简历已上传", "resume": resume, "talent": talent})
+		return
+	}
+	if err := db.ApplyProfile(talent, parsed, resume, db.TriggerUpload, auditActor(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新人才信息失败", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "简历已上传并生效", "resume": resume, "talent": parsed})
+}
+
+// activateResumeVersion makes a resume version drive the talent's profile, re-scoring it with
+// the current rules. Versions that were never parsed are parsed from their file first.
+func activateResumeVersion(c *gin.Context) {
+	talent, err := db.GetTalent(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "人才信息未找到", "details": err.Error()})
+		return
+	}
+	resume, err := db.GetResume(talent.ID, c.Param("resumeId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "简历版本未找到", "details": err.Error()})
+		return
+	}
+
+	parsed, err := resume.Parsed()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "解析结果无效", "details": err.Error()})
+		return
+	}
+	if parsed == nil {
+		if _, err := os.Stat(resume.Path); os.IsNotExist(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "简历文件不存在"})
+			return
+		}
+		var text string
+		parsed, text, err = pdf.ParseResume(resume.Path)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "解析简历失败", "details": err.Error()})
+			return
+		}
+		resume.Text = text
+		if err := resume.SetParsed(parsed); err == nil {
+			if err := db.UpdateResumeParse(resume); err != nil {
+				fmt.Printf("Error saving resume parse result: %v\n", err)
+			}
+		}
+	}
+	parsed.CalcScore()
+
+	if err := db.ApplyProfile(talent, parsed, resume, db.TriggerResume, auditActor(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新人才信息失败", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "简历版本已生效", "resume": resume, "talent": parsed})
+}
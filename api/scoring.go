@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+
+	"talents/db"
+	"talents/scoring"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getScoringRules returns the active scoring rule set
+func getScoringRules(c *gin.Context) {
+	c.JSON(http.StatusOK, scoring.Active())
+}
+
+// reloadScoringRules reloads the scoring rules from the configured rule file
+func reloadScoringRules(c *gin.Context) {
+	old := scoring.Active().Version
+	rules, err := scoring.Reload()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "评分规则加载失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "评分规则已重新加载",
+		"old_version": old,
+		"version":     rules.Version,
+		"rules":       rules,
+	})
+}
+
+// getScoreExplanation returns the per-rule breakdown behind a talent's scores
+func getScoreExplanation(c *gin.Context) {
+	id := c.Param("id")
+	talent, err := db.GetTalent(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "人才信息未找到", "details": err.Error()})
+		return
+	}
+
+	explanation := talent.ExplainScore()
+	// 规则或档案变更后尚未重新计算时，明细与已保存的分数不一致
+	stale := talent.ScoreVersion != explanation.ScoreVersion ||
+		talent.AverageScore != explanation.AverageScore
+
+	c.JSON(http.StatusOK, gin.H{
+		"explanation": explanation,
+		"stale":       stale,
+		"stored": gin.H{
+			"ruleVersion":     talent.ScoreVersion,
+			"experienceScore": talent.ExperienceScore,
+			"educationScore":  talent.EducationScore,
+			"technicalScore":  talent.TechnicalScore,
+			"intentScore":     talent.IntentScore,
+			"averageScore":    talent.AverageScore,
+		},
+	})
+}
+
+// getScoreHistory returns every recorded score snapshot of a talent
+func getScoreHistory(c *gin.Context) {
+	id := c.Param("id")
+	talent, err := db.GetTalent(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "人才信息未找到", "details": err.Error()})
+		return
+	}
+
+	history, err := db.ListScoreHistory(talent.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
@@ -36,7 +36,7 @@ function generateInterviewQuestions(talent) {
   const controller = new AbortController();
   const timeoutId = setTimeout(() => controller.abort(), 60000); // 60s timeout for LLM
 
-  fetch(`/talent/${talent.phone}/generate-interview-questions`, {
+  fetch(`/talent/${talent.id}/generate-interview-questions`, {
     method: "POST",
     signal: controller.signal,
   })
@@ -136,7 +136,7 @@ function setupAutoSaveInterviewRecord(talent) {
     const controller = new AbortController();
     const timeoutId = setTimeout(() => controller.abort(), 10000); // 10s timeout
 
-    fetch(`/talent/${talent.phone}/interview-record`, {
+    fetch(`/talent/${talent.id}/interview-record`, {
       method: "POST",
       headers: {
         "Content-Type": "application/json",
@@ -167,10 +167,12 @@ function setupAutoSaveInterviewRecord(talent) {
         // Update local talentsData to reflect the saved interview record
         if (talentsData && Array.isArray(talentsData)) {
           const talentIndex = talentsData.findIndex(
-            (t) => t.phone == talent.phone,
+            (t) => t.id == talent.id,
           );
           if (talentIndex !== -1) {
-            talentsData[talentIndex].interviewRecord = currentContent;
+            const interviews = talentsData[talentIndex].interviews || [];
+            interviews[0] = data.interview;
+            talentsData[talentIndex].interviews = interviews;
             console.log("本地数据已更新:", talentsData[talentIndex]);
           }
         }
@@ -529,7 +531,7 @@ function renderTalentList(talents) {
     detailsButton.addEventListener("click", function () {
       console.log("查看详情按钮被点击", talent);
       // Find the latest talent data from talentsData to ensure we have the most recent interview record
-      const latestTalent = talentsData.find((t) => t.phone === talent.phone);
+      const latestTalent = talentsData.find((t) => t.id === talent.id);
       showTalentDetails(latestTalent || talent);
     });
 
@@ -756,6 +758,12 @@ function showTalentDetails(talent) {
                             <span class="score-value">${talent.technicalScore?.toFixed(1) || "-"}</span>
                         </div>
                     </div>
+                    <div class="score-metric">
+                        <div class="score-label">意向</div>
+                        <div class="cyber-score-circle" style="--score-color: ${scoreColor}">
+                            <span class="score-value">${talent.intentScore?.toFixed(1) || "-"}</span>
+                        </div>
+                    </div>
                     <div class="score-metric">
                         <div class="score-label">平均</div>
                         <div class="cyber-score-circle average" style="--score-color: ${scoreColor}">
@@ -783,7 +791,7 @@ function showTalentDetails(talent) {
                               <span class="btn-glow"></span>
                           </button>
                       </div>
-                      <textarea id="interviewRecordText" class="form-control cyber-textarea mb-2 auto-resize" style="min-height: 100px; height: auto;" placeholder="请输入面试记录...">${escapeHtml(talent.interviewRecord || "")}</textarea>
+                      <textarea id="interviewRecordText" class="form-control cyber-textarea mb-2 auto-resize" style="min-height: 100px; height: auto;" placeholder="请输入面试记录...">${escapeHtml((talent.interviews && talent.interviews[0] && talent.interviews[0].notes) || "")}</textarea>
                       <div id="interviewRecordStatus" class="text-muted small mt-1" style="font-size: 0.8rem;">
                           <i class="bi bi-check-circle text-success"></i> 已保存
                       </div>
@@ -898,9 +906,9 @@ function showTalentDetails(talent) {
      // Set up reparse resume button
      const reparseResumeBtn = document.getElementById("reparseResumeBtn");
      if (reparseResumeBtn) {
-       reparseResumeBtn.setAttribute("data-phone", talent.phone);
+       reparseResumeBtn.setAttribute("data-id", talent.id);
        reparseResumeBtn.addEventListener("click", function () {
-         const phone = this.getAttribute("data-phone");
+         const id = this.getAttribute("data-id");
 
          // Confirm action
          if (!confirm("确定要重新解析该人才的简历吗？这将覆盖当前的所有信息。")) {
@@ -916,7 +924,7 @@ function showTalentDetails(talent) {
          const controller = new AbortController();
          const timeoutId = setTimeout(() => controller.abort(), 30000); // 30s timeout for parsing
 
-         fetch(`/talent/${phone}/reparse-resume`, {
+         fetch(`/talent/${id}/reparse-resume`, {
            method: "POST",
            signal: controller.signal,
          })
@@ -943,7 +951,7 @@ function showTalentDetails(talent) {
              // Update local talentsData
              if (talentsData && Array.isArray(talentsData)) {
                const talentIndex = talentsData.findIndex(
-                 (t) => t.phone == phone,
+                 (t) => t.id == id,
                );
                if (talentIndex !== -1) {
                  talentsData[talentIndex] = data.talent;
@@ -1339,7 +1347,7 @@ function showBatchUploadResults(data, container) {
         <small>${filename}</small>
         <div><strong>${talentName}</strong> 档案已在系统中存在</div>
         <div><button class="btn btn-sm btn-outline-primary mt-1 view-duplicate-details"
-          data-phone="${duplicate.existing_talent ? duplicate.existing_talent.phone : ""}">
+          data-id="${duplicate.existing_talent ? duplicate.existing_talent.id : ""}">
           查看已存在档案</button></div>
       </li>`;
     });
@@ -1381,11 +1389,11 @@ function showBatchUploadResults(data, container) {
   );
   viewDuplicateButtons.forEach((button) => {
     button.addEventListener("click", function () {
-      const phone = this.getAttribute("data-phone");
-      if (phone) {
+      const id = this.getAttribute("data-id");
+      if (id) {
         // Find the talent in the current data
         const duplicate = duplicates.find(
-          (d) => d.existing_talent && d.existing_talent.phone == phone,
+          (d) => d.existing_talent && d.existing_talent.id == id,
         );
 
         if (duplicate && duplicate.existing_talent) {
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"talents/db"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func listTrash(c *gin.Context) {
+	talents, err := db.ListTrashedTalents()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, talents)
+}
+
+func restoreTalent(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := db.GetTrashedTalent(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "回收站中没有该人才", "details": err.Error()})
+		return
+	}
+	if err := db.RestoreTalent(id, auditActor(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Talent restored successfully"})
+}
+
+// purgeTalent permanently removes a talent from the trash without waiting for the retention period
+func purgeTalent(c *gin.Context) {
+	talent, err := db.GetTrashedTalent(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "回收站中没有该人才", "details": err.Error()})
+		return
+	}
+	if err := db.PurgeTalent(talent.ID, auditActor(c)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Talent purged successfully"})
+}
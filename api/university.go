@@ -0,0 +1,146 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"talents/db"
+	"talents/university"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// listUnresolvedUniversities returns stored university names that the registry cannot resolve
+func listUnresolvedUniversities(c *gin.Context) {
+	unresolved, err := db.UnresolvedUniversities()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, unresolved)
+}
+
+// universityConditions reads the tier and csRating query parameters
+func universityConditions(c *gin.Context) (string, string, error) {
+	tier, csRating := c.Query("tier"), c.Query("csRating")
+	if tier != "" && !university.ValidTier(tier) {
+		return "", "", fmt.Errorf("unknown tier %q, expected 985, 211 or 双一流", tier)
+	}
+	if csRating != "" && !university.ValidCSRating(csRating) {
+		return "", "", fmt.Errorf("unknown csRating %q", csRating)
+	}
+	return tier, csRating, nil
+}
+
+// listUniversities resolves a single name when name is given, otherwise lists the universities
+// matching the query, tier, csRating and country filters
+func listUniversities(c *gin.Context) {
+	if name := c.Query("name"); name != "" {
+		u, ok := university.Resolve(name)
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "院校未找到"})
+			return
+		}
+		c.JSON(http.StatusOK, u)
+		return
+	}
+
+	tier, csRating, err := universityConditions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, university.List(university.Filter{
+		Query:    c.Query("query"),
+		Tier:     tier,
+		CSRating: csRating,
+		Country:  c.Query("country"),
+	}))
+}
+
+// listUniversityRankings returns the active ranking version and every uploaded version
+func listUniversityRankings(c *gin.Context) {
+	rankings, err := db.ListUniversityRankings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"active":   university.Version(),
+		"versions": rankings,
+	})
+}
+
+// rankingActivated builds the response after switching the ranking, recalculating all
+// scores when recalculate=true
+func rankingActivated(c *gin.Context, message string, body gin.H) {
+	body["message"] = message
+	body["active"] = university.Version()
+	if c.Query("recalculate") == "true" {
+		result, err := db.RecalculateAllTalentScores(db.TriggerRanking, auditActor(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "分数重新计算失败", "details": err.Error()})
+			return
+		}
+		body["recalculation"] = result
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+// uploadUniversityRanking stores a new ranking in the University JSON format, sent either as
+// the file form field or as the request body. activate=true switches to it immediately.
+func uploadUniversityRanking(c *gin.Context) {
+	var data []byte
+	var err error
+	if file, _, ferr := c.Request.FormFile("file"); ferr == nil {
+		defer file.Close()
+		data, err = io.ReadAll(file)
+	} else {
+		data, err = io.ReadAll(c.Request.Body)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "读取院校排名数据失败", "details": err.Error()})
+		return
+	}
+
+	version := c.Query("version")
+	if version == "" {
+		version = time.Now().Format("20060102150405")
+	}
+	ranking, err := db.CreateUniversityRanking(version, data, getUID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的院校排名数据", "details": err.Error()})
+		return
+	}
+	if c.Query("activate") != "true" {
+		c.JSON(http.StatusCreated, ranking)
+		return
+	}
+
+	if err := db.ActivateUniversityRanking(ranking.Version); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "切换院校排名数据失败", "details": err.Error()})
+		return
+	}
+	ranking.Active = true
+	rankingActivated(c, "院校排名数据已上传并生效", gin.H{"ranking": ranking})
+}
+
+// activateUniversityRanking switches to a stored ranking version, or back to the builtin data
+func activateUniversityRanking(c *gin.Context) {
+	version := c.Param("version")
+	if err := db.ActivateUniversityRanking(version); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": "切换院校排名数据失败", "details": err.Error()})
+		return
+	}
+	rankingActivated(c, "院校排名数据已切换", gin.H{})
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+
+	"talents/db"
+	"talents/scoring"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listWeights returns the default weights and the per-position overrides
+func listWeights(c *gin.Context) {
+	profiles, err := db.ListWeightProfiles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"default":   scoring.Active().Weights,
+		"positions": profiles,
+	})
+}
+
+// updateWeights sets the weight profile of a position and recalculates all scores with it
+func updateWeights(c *gin.Context) {
+	var weights scoring.Weights
+	if err := c.ShouldBindJSON(&weights); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据", "details": err.Error()})
+		return
+	}
+	if err := weights.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的权重", "details": err.Error()})
+		return
+	}
+
+	profile := &db.WeightProfile{
+		JobPosition: c.Param("position"),
+		Experience:  weights.Experience,
+		Education:   weights.Education,
+		Technical:   weights.Technical,
+		Intent:      weights.Intent,
+		UpdatedBy:   getUID(c),
+	}
+	result, err := db.SaveWeightProfile(profile, auditActor(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存权重失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "权重已更新",
+		"profile":       profile,
+		"recalculation": result,
+	})
+}
+
+// deleteWeights removes a position's weight profile so it falls back to the default weights
+func deleteWeights(c *gin.Context) {
+	result, err := db.DeleteWeightProfile(c.Param("position"), auditActor(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除权重失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "已恢复默认权重",
+		"recalculation": result,
+	})
+}
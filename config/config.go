@@ -1,7 +1,9 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -13,38 +15,54 @@ var TIKA_URL string
 var LLM_URL string
 var LLM_KEY string
 var LLM_MODEL string
+var SCORING_RULES string      // 评分规则文件路径，为空时使用内置规则
+var TRASH_RETENTION_DAYS = 30 // 回收站保留天数，超过后彻底删除，为 0 时不自动清理
+var DB_DRIVER = "sqlite"      // 数据库驱动：sqlite、postgres 或 mysql
+var DB_DSN string             // sqlite 为数据库文件路径，默认 talents.db；postgres、mysql 为连接串
 
-func init() {
-	err := godotenv.Load()
-	if err != nil {
-		panic("Error loading .env file")
-	}
-	SECRETKEY = os.Getenv("SECRETKEY")
-	if SECRETKEY == "" {
-		panic("SECRETKEY is not set")
-	}
-	HEADER = os.Getenv("HEADER")
-	if HEADER == "" {
-		panic("HEADER is not set")
+// MaxTrashRetentionDays 回收站最长保留 100 年，保证换算成 time.Duration 时不会溢出
+const MaxTrashRetentionDays = 36500
+
+// Load 从 .env 与环境变量读取配置，由 main 在启动时调用。单元测试不调用 Load，直接给变量赋值
+func Load() error {
+	if err := godotenv.Load(); err != nil {
+		return errors.New("Error loading .env file")
 	}
-	AUTH_URL = os.Getenv("AUTH_URL")
-	if AUTH_URL == "" {
-		panic("AUTH_URL is not set")
+	required := []struct {
+		value *string
+		name  string
+	}{
+		{&SECRETKEY, "SECRETKEY"},
+		{&HEADER, "HEADER"},
+		{&AUTH_URL, "AUTH_URL"},
+		{&TIKA_URL, "TIKA_URL"},
+		{&LLM_URL, "LLM_URL"},
+		{&LLM_KEY, "LLM_KEY"},
+		{&LLM_MODEL, "LLM_MODEL"},
 	}
-	TIKA_URL = os.Getenv("TIKA_URL")
-	if TIKA_URL == "" {
-		panic("TIKA_SERVER is not set")
+	for _, v := range required {
+		*v.value = os.Getenv(v.name)
+		if *v.value == "" {
+			return errors.New(v.name + " is not set")
+		}
 	}
-	LLM_URL = os.Getenv("LLM_URL")
-	if LLM_URL == "" {
-		panic("LLM_URL is not set")
+	SCORING_RULES = os.Getenv("SCORING_RULES")
+	if driver := os.Getenv("DB_DRIVER"); driver != "" {
+		DB_DRIVER = driver
 	}
-	LLM_KEY = os.Getenv("LLM_KEY")
-	if LLM_KEY == "" {
-		panic("LLM_KEY is not set")
+	DB_DSN = os.Getenv("DB_DSN")
+	if DB_DSN == "" {
+		if DB_DRIVER != "sqlite" {
+			return errors.New("DB_DSN is not set")
+		}
+		DB_DSN = "talents.db"
 	}
-	LLM_MODEL = os.Getenv("LLM_MODEL")
-	if LLM_MODEL == "" {
-		panic("LLM_MODEL is not set")
+	if days := os.Getenv("TRASH_RETENTION_DAYS"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 || n > MaxTrashRetentionDays {
+			return errors.New("TRASH_RETENTION_DAYS must be a number of days between 0 and " + strconv.Itoa(MaxTrashRetentionDays))
+		}
+		TRASH_RETENTION_DAYS = n
 	}
+	return nil
 }
@@ -0,0 +1,242 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrAuditAppendOnly is returned when an audit log entry would be modified or deleted
+var ErrAuditAppendOnly = errors.New("audit log is append-only")
+
+// FieldChange 字段变更前后的值，新建时 Before 为空，彻底删除时 After 为空
+type FieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditChanges 按字段（JSON 字段名）记录的变更
+type AuditChanges map[string]FieldChange
+
+// Scan implements the sql.Scanner interface
+func (a *AuditChanges) Scan(value interface{}) error {
+	return scanJSON(value, a, "AuditChanges")
+}
+
+// Value implements the driver.Valuer interface
+func (a AuditChanges) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return valueJSON(a)
+}
+
+// AuditLog 一次对人才数据的修改，与修改在同一事务中写入，只追加不修改。
+// 只追加由下面的 gorm 钩子保证，Exec、Raw 等原生 SQL 不经过钩子，也不受数据库约束；
+// 需要更强保证时应收回应用数据库账号对 audit_logs 的 UPDATE 和 DELETE 权限
+type AuditLog struct {
+	ID        uint         `gorm:"primaryKey" json:"id"`
+	UserID    uint         `gorm:"index" json:"userId"`
+	Endpoint  string       `json:"endpoint"` // 如 PUT /talent/:id
+	TalentID  uint64       `gorm:"index" json:"talentId"`
+	Changes   AuditChanges `gorm:"type:text" json:"changes"`
+	CreatedAt time.Time    `gorm:"index" json:"createdAt"`
+}
+
+// BeforeUpdate keeps audit log entries immutable
+func (a *AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditAppendOnly
+}
+
+// BeforeDelete keeps audit log entries from being removed
+func (a *AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditAppendOnly
+}
+
+// auditIgnored 不计入审计差异的字段，面试等由各自的接口维护
+var auditIgnored = []string{"interviews"}
+
+// auditFields flattens a talent into its JSON fields. Row ids of the employment and education
+// history are dropped since they are regenerated whenever the history is replaced.
+func auditFields(t *Talent) (map[string]any, error) {
+	fields := map[string]any{}
+	if t == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, name := range auditIgnored {
+		delete(fields, name)
+	}
+	for _, name := range []string{"employments", "educations"} {
+		list, _ := fields[name].([]any)
+		if len(list) == 0 {
+			fields[name] = nil
+		}
+		for _, item := range list {
+			if m, ok := item.(map[string]any); ok {
+				delete(m, "id")
+				delete(m, "talentId")
+			}
+		}
+	}
+	return fields, nil
+}
+
+// DiffTalents returns the fields that differ between two versions of a talent. A nil before
+// means the talent was created, a nil after that it was purged.
+func DiffTalents(before, after *Talent) (AuditChanges, error) {
+	old, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+	changes := AuditChanges{}
+	for name, value := range cur {
+		if !reflect.DeepEqual(old[name], value) {
+			changes[name] = FieldChange{Before: old[name], After: value}
+		}
+	}
+	for name, value := range old {
+		if _, ok := cur[name]; !ok && value != nil {
+			changes[name] = FieldChange{Before: value}
+		}
+	}
+	return changes, nil
+}
+
+// Actor 发起修改的用户与接口。Endpoint 为空表示内部任务（如定时清理），不记录审计日志
+type Actor struct {
+	UserID   uint
+	Endpoint string // 如 PUT /talent/:id
+}
+
+// audited reports whether changes made by the actor are recorded in the audit log
+func (a Actor) audited() bool {
+	return a.Endpoint != ""
+}
+
+// auditedTalent loads a talent for the audit log, including one in the trash, nil if it does not exist
+func auditedTalent(tx *gorm.DB, talentID uint64) (*Talent, error) {
+	var talent Talent
+	err := preloadDetails(tx).Unscoped().First(&talent, talentID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &talent, nil
+}
+
+// auditTalent runs change in a transaction and appends the audit entry for what it did to the
+// talent in the same transaction, so a change never commits without its entry and failing to
+// write the entry rolls the change back
+func auditTalent(actor Actor, talentID uint64, change func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if !actor.audited() {
+			return change(tx)
+		}
+		before, err := auditedTalent(tx, talentID)
+		if err != nil {
+			return err
+		}
+		if err := change(tx); err != nil {
+			return err
+		}
+		after, err := auditedTalent(tx, talentID)
+		if err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, talentID, before, after)
+	})
+}
+
+// recordAudit appends an audit entry for a change of a talent, nothing is recorded when no field
+// changed or the actor is internal
+func recordAudit(tx *gorm.DB, actor Actor, talentID uint64, before, after *Talent) error {
+	if !actor.audited() {
+		return nil
+	}
+	changes, err := DiffTalents(before, after)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return tx.Create(&AuditLog{UserID: actor.UserID, Endpoint: actor.Endpoint, TalentID: talentID, Changes: changes}).Error
+}
+
+// recordRecalculationAudit appends an audit entry for every talent whose scores an applied
+// recalculation changed
+func recordRecalculationAudit(tx *gorm.DB, actor Actor, result *RecalculationResult) error {
+	if !actor.audited() || len(result.ScoreChanges) == 0 {
+		return nil
+	}
+	logs := make([]*AuditLog, 0, len(result.ScoreChanges))
+	for _, sc := range result.ScoreChanges {
+		changes := AuditChanges{}
+		for name, values := range map[string][2]float32{
+			"experienceScore": {sc.OldExpScore, sc.NewExpScore},
+			"educationScore":  {sc.OldEduScore, sc.NewEduScore},
+			"technicalScore":  {sc.OldTechScore, sc.NewTechScore},
+			"intentScore":     {sc.OldIntScore, sc.NewIntScore},
+			"averageScore":    {sc.OldAvgScore, sc.NewAvgScore},
+		} {
+			if values[0] != values[1] {
+				changes[name] = FieldChange{Before: values[0], After: values[1]}
+			}
+		}
+		logs = append(logs, &AuditLog{UserID: actor.UserID, Endpoint: actor.Endpoint, TalentID: sc.Talent.ID, Changes: changes})
+	}
+	return tx.CreateInBatches(logs, 100).Error
+}
+
+// AuditFilter 审计日志查询条件，为空的条件不参与筛选
+type AuditFilter struct {
+	UserID   *uint
+	TalentID *uint64
+	Since    *time.Time
+	Until    *time.Time
+	Limit    int
+	Offset   int
+}
+
+// ListAuditLogs returns audit entries matching the filter, newest first, and the total count
+func ListAuditLogs(f AuditFilter) ([]*AuditLog, int64, error) {
+	qry := db.Model(&AuditLog{})
+	if f.UserID != nil {
+		qry = qry.Where("user_id = ?", *f.UserID)
+	}
+	if f.TalentID != nil {
+		qry = qry.Where("talent_id = ?", *f.TalentID)
+	}
+	if f.Since != nil {
+		qry = qry.Where("created_at >= ?", *f.Since)
+	}
+	if f.Until != nil {
+		qry = qry.Where("created_at < ?", *f.Until)
+	}
+	var total int64
+	if err := qry.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var logs []*AuditLog
+	if err := qry.Order("created_at DESC, id DESC").Limit(f.Limit).Offset(f.Offset).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
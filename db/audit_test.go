@@ -0,0 +1,79 @@
+package db
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAuditTalent(t *testing.T) {
+	actor := Actor{UserID: 1, Endpoint: "PUT /talent/:id/tags"}
+	tests := []struct {
+		name       string
+		actor      Actor
+		failAudit  bool
+		wantTags   []string
+		wantLogged int
+		wantErr    bool
+	}{
+		{name: "recorded with the change", actor: actor, wantTags: []string{"内推"}, wantLogged: 1},
+		{name: "internal change", actor: Actor{UserID: 1}, wantTags: []string{"内推"}},
+		{name: "failed entry rolls back", actor: actor, failAudit: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openTestDB(t)
+			talent := &Talent{Name: "甲"}
+			if err := CreateTalent(talent, Actor{}); err != nil {
+				t.Fatal(err)
+			}
+			if tt.failAudit {
+				exec(t, "CREATE TRIGGER fail_audit BEFORE INSERT ON audit_logs BEGIN SELECT RAISE(ABORT, 'audit unavailable'); END")
+			}
+
+			_, err := SetTalentTags(talent.ID, []string{"内推"}, tt.actor)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetTalentTags = %v, want error %v", err, tt.wantErr)
+			}
+			var got Talent
+			if err := db.First(&got, talent.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Tags) != len(tt.wantTags) {
+				t.Errorf("tags = %v, want %v", got.Tags, tt.wantTags)
+			}
+			logs, total, err := ListAuditLogs(AuditFilter{Limit: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int(total) != tt.wantLogged {
+				t.Fatalf("audit entries = %d, want %d", total, tt.wantLogged)
+			}
+			if total > 0 {
+				if logs[0].Endpoint != actor.Endpoint || logs[0].TalentID != talent.ID {
+					t.Errorf("entry = %s for %d", logs[0].Endpoint, logs[0].TalentID)
+				}
+				if _, ok := logs[0].Changes["tags"]; !ok || len(logs[0].Changes) != 1 {
+					t.Errorf("changes = %v, want only tags", logs[0].Changes)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditLogAppendOnly(t *testing.T) {
+	openTestDB(t)
+	talent := &Talent{Name: "甲"}
+	if err := CreateTalent(talent, Actor{UserID: 1, Endpoint: "POST /talent"}); err != nil {
+		t.Fatal(err)
+	}
+	var entry AuditLog
+	if err := db.First(&entry).Error; err != nil {
+		t.Fatalf("creation was not audited: %v", err)
+	}
+	if err := db.Model(&entry).Update("user_id", 2).Error; !errors.Is(err, ErrAuditAppendOnly) {
+		t.Errorf("update = %v, want ErrAuditAppendOnly", err)
+	}
+	if err := db.Delete(&entry).Error; !errors.Is(err, ErrAuditAppendOnly) {
+		t.Errorf("delete = %v, want ErrAuditAppendOnly", err)
+	}
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotCommentAuthor is returned when a user edits or deletes someone else's comment
+var ErrNotCommentAuthor = errors.New("only the author can change a comment")
+
+// Comment 人才的评论，ParentID 不为空时是对另一条评论的回复
+type Comment struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	TalentID  uint64     `gorm:"index" json:"talentId"`
+	ParentID  *uint      `gorm:"index" json:"parentId"`
+	AuthorID  uint       `gorm:"index" json:"authorId"`
+	Body      string     `gorm:"type:text" json:"body"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	Replies   []*Comment `gorm:"-" json:"replies,omitempty"`
+}
+
+func validateCommentBody(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return errors.New("comment is empty")
+	}
+	if len(body) > 10000 {
+		return errors.New("comment too long")
+	}
+	return nil
+}
+
+// CreateComment saves a comment, a reply must belong to the same talent as its parent
+func CreateComment(c *Comment) error {
+	if err := validateCommentBody(c.Body); err != nil {
+		return err
+	}
+	if c.ParentID != nil {
+		var parent Comment
+		if err := db.Where("talent_id = ?", c.TalentID).First(&parent, *c.ParentID).Error; err != nil {
+			return errors.New("parent comment not found")
+		}
+	}
+	return db.Create(c).Error
+}
+
+// ListComments returns the comment threads of a talent, oldest first, with replies nested
+func ListComments(talentID uint64) ([]*Comment, error) {
+	var comments []*Comment
+	if err := db.Where("talent_id = ?", talentID).Order("created_at, id").Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*Comment, len(comments))
+	for _, c := range comments {
+		byID[c.ID] = c
+	}
+	threads := make([]*Comment, 0)
+	for _, c := range comments {
+		if c.ParentID != nil && byID[*c.ParentID] != nil {
+			parent := byID[*c.ParentID]
+			parent.Replies = append(parent.Replies, c)
+			continue
+		}
+		threads = append(threads, c)
+	}
+	return threads, nil
+}
+
+// getOwnComment returns a comment of a talent that was written by uid
+func getOwnComment(talentID uint64, id string, uid uint) (*Comment, error) {
+	commentID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	var c Comment
+	if err := db.Where("talent_id = ?", talentID).First(&c, commentID).Error; err != nil {
+		return nil, err
+	}
+	if c.AuthorID != uid {
+		return nil, ErrNotCommentAuthor
+	}
+	return &c, nil
+}
+
+// UpdateComment changes the body of a comment written by uid
+func UpdateComment(talentID uint64, id string, uid uint, body string) (*Comment, error) {
+	if err := validateCommentBody(body); err != nil {
+		return nil, err
+	}
+	c, err := getOwnComment(talentID, id, uid)
+	if err != nil {
+		return nil, err
+	}
+	c.Body = body
+	if err := db.Model(c).Update("body", body).Error; err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// DeleteComment deletes a comment written by uid together with all replies under it
+func DeleteComment(talentID uint64, id string, uid uint) error {
+	c, err := getOwnComment(talentID, id, uid)
+	if err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		ids := []uint{c.ID}
+		for parents := ids; len(parents) > 0; {
+			var children []uint
+			if err := tx.Model(&Comment{}).Where("parent_id IN ?", parents).Pluck("id", &children).Error; err != nil {
+				return err
+			}
+			ids = append(ids, children...)
+			parents = children
+		}
+		return tx.Delete(&Comment{}, ids).Error
+	})
+}
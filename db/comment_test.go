@@ -0,0 +1,78 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestCommentIDs(t *testing.T) {
+	openTestDB(t)
+	talent := &Talent{Name: "甲"}
+	if err := CreateTalent(talent, Actor{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, body := range []string{"第一条", "第二条"} {
+		if err := CreateComment(&Comment{TalentID: talent.ID, AuthorID: 1, Body: body}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []string{"1 OR 1=1", "abc", "0", "3"} {
+		if err := DeleteComment(talent.ID, id, 1); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("DeleteComment(%q) = %v, want not found", id, err)
+		}
+	}
+	if err := DeleteComment(talent.ID, "1", 2); !errors.Is(err, ErrNotCommentAuthor) {
+		t.Errorf("DeleteComment by another user = %v, want ErrNotCommentAuthor", err)
+	}
+	comments, err := ListComments(talent.ID)
+	if err != nil || len(comments) != 2 {
+		t.Fatalf("ListComments = %d comments, %v, want 2", len(comments), err)
+	}
+}
+
+func TestSearchTalentsByComment(t *testing.T) {
+	openTestDB(t)
+	bodies := map[string]string{"甲": "薪资要求 100% 涨幅", "乙": "薪资要求 100 万", "丙": "a_b 项目"}
+	for _, name := range []string{"甲", "乙", "丙"} {
+		talent := &Talent{Name: name}
+		if err := CreateTalent(talent, Actor{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := CreateComment(&Comment{TalentID: talent.ID, AuthorID: 1, Body: bodies[name]}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		comment string
+		want    []string
+	}{
+		{"100%", []string{"甲"}},
+		{"100", []string{"甲", "乙"}},
+		{"a_b", []string{"丙"}},
+		{"_", []string{"丙"}},
+		{"%", []string{"甲"}},
+		{"!", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.comment, func(t *testing.T) {
+			talents, err := SearchTalents(TalentFilter{Comment: tt.comment})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, talent := range talents {
+				got = append(got, talent.Name)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("SearchTalents(%q) = %v, want %v", tt.comment, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("SearchTalents(%q) = %v, want %v", tt.comment, got, tt.want)
+				}
+			}
+		})
+	}
+}
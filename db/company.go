@@ -0,0 +1,237 @@
+package db
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// Company 公司库条目，Tier 对应评分规则中的公司档位名称
+type Company struct {
+	ID       uint        `gorm:"primaryKey" json:"id"`
+	Name     string      `gorm:"size:191;uniqueIndex" json:"name"` // 标准名称
+	Aliases  StringSlice `gorm:"type:text" json:"aliases"`         // 别名，包括英文名和子公司
+	Tier     string      `json:"tier"`
+	Industry string      `json:"industry"`
+}
+
+//go:embed companies.json
+var _companies []byte
+
+// companyCache caches the registry so that scoring doesn't hit the database per talent
+var companyCache struct {
+	sync.RWMutex
+	companies []*Company
+}
+
+// seedCompanies fills an empty registry with the embedded company list
+func seedCompanies() error {
+	var count int64
+	if err := db.Model(&Company{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	companies := []*Company{}
+	if err := json.Unmarshal(_companies, &companies); err != nil {
+		return err
+	}
+	return db.Create(&companies).Error
+}
+
+func loadCompanies() error {
+	companies, err := ListCompanies()
+	if err != nil {
+		return err
+	}
+	companyCache.Lock()
+	companyCache.companies = companies
+	companyCache.Unlock()
+	return nil
+}
+
+// normalizeCompanyName lowercases a name and drops spaces and full-width brackets
+func normalizeCompanyName(name string) string {
+	name = strings.NewReplacer("（", "(", "）", ")").Replace(name)
+	return strings.Join(strings.Fields(strings.ToLower(name)), "")
+}
+
+func isASCIIAlnum(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// shortAliasLen 少于该字数的别名（如 淘宝、微信）只在前后不是文字时匹配
+const shortAliasLen = 3
+
+// containsName reports whether name contains alias. ASCII aliases must match a whole word
+// so that "jd" doesn't match "jdbc", and when delimited is set a short alias must not touch
+// any letter so that 阿里 doesn't match 阿里山茶业.
+func containsName(name, alias string, delimited bool) bool {
+	bounded := func(neighbour, edge rune) bool {
+		if isASCIIAlnum(edge) {
+			return !isASCIIAlnum(neighbour)
+		}
+		return !delimited || !unicode.IsLetter(neighbour) && !unicode.IsDigit(neighbour)
+	}
+	first, _ := utf8.DecodeRuneInString(alias)
+	last, _ := utf8.DecodeLastRuneInString(alias)
+	for offset := 0; ; {
+		i := strings.Index(name[offset:], alias)
+		if i == -1 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(alias)
+		before, _ := utf8.DecodeLastRuneInString(name[:start])
+		after, _ := utf8.DecodeRuneInString(name[end:])
+		if (start == 0 || bounded(before, first)) && (end == len(name) || bounded(after, last)) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// ResolveCompany finds the registry entry for a company name. Exact name or alias matches
+// win, otherwise the entry with the longest name or alias contained in the given name is used.
+// Aliases shorter than shortAliasLen only count when delimited, e.g. by brackets.
+func ResolveCompany(name string) *Company {
+	key := normalizeCompanyName(name)
+	if key == "" {
+		return nil
+	}
+	companyCache.RLock()
+	defer companyCache.RUnlock()
+
+	var best *Company
+	bestLen := 0
+	for _, c := range companyCache.companies {
+		for i, n := range append([]string{c.Name}, c.Aliases...) {
+			n = normalizeCompanyName(n)
+			if n == key {
+				return c
+			}
+			// 过短的别名容易误匹配
+			runes := utf8.RuneCountInString(n)
+			if runes < 2 || len(n) <= bestLen {
+				continue
+			}
+			if containsName(key, n, i > 0 && runes < shortAliasLen) {
+				best, bestLen = c, len(n)
+			}
+		}
+	}
+	return best
+}
+
+// NormalizeCompanies replaces company names found in the registry with their canonical names
+func NormalizeCompanies(companies StringSlice) StringSlice {
+	if companies == nil {
+		return nil
+	}
+	normalized := make(StringSlice, 0, len(companies))
+	seen := make(map[string]bool)
+	for _, name := range companies {
+		if c := ResolveCompany(name); c != nil {
+			name = c.Name
+		}
+		if !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized
+}
+
+func validateCompany(c *Company) error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return errors.New("company name is required")
+	}
+	aliases := make(StringSlice, 0, len(c.Aliases))
+	for _, alias := range c.Aliases {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+	c.Aliases = aliases
+
+	companyCache.RLock()
+	defer companyCache.RUnlock()
+	for _, other := range companyCache.companies {
+		if other.ID == c.ID {
+			continue
+		}
+		names := make(map[string]bool)
+		for _, n := range append([]string{other.Name}, other.Aliases...) {
+			names[normalizeCompanyName(n)] = true
+		}
+		for _, n := range append([]string{c.Name}, c.Aliases...) {
+			if names[normalizeCompanyName(n)] {
+				return fmt.Errorf("%q is already used by company %q", n, other.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func ListCompanies() ([]*Company, error) {
+	var companies []*Company
+	if err := db.Order("id").Find(&companies).Error; err != nil {
+		return nil, err
+	}
+	return companies, nil
+}
+
+func GetCompany(id string) (*Company, error) {
+	companyID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	var company Company
+	if err := db.First(&company, companyID).Error; err != nil {
+		return nil, err
+	}
+	return &company, nil
+}
+
+func CreateCompany(c *Company) error {
+	if err := validateCompany(c); err != nil {
+		return err
+	}
+	if err := db.Create(c).Error; err != nil {
+		return err
+	}
+	return loadCompanies()
+}
+
+func UpdateCompany(c *Company) error {
+	if err := validateCompany(c); err != nil {
+		return err
+	}
+	if err := db.Save(c).Error; err != nil {
+		return err
+	}
+	return loadCompanies()
+}
+
+func DeleteCompany(id string) error {
+	companyID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	result := db.Delete(&Company{}, companyID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return loadCompanies()
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestResolveCompany(t *testing.T) {
+	companyCache.companies = []*Company{
+		{ID: 1, Name: "阿里巴巴", Aliases: StringSlice{"阿里", "淘宝", "Alibaba"}},
+		{ID: 2, Name: "腾讯", Aliases: StringSlice{"微信", "Tencent"}},
+		{ID: 3, Name: "京东", Aliases: StringSlice{"JD"}},
+	}
+	t.Cleanup(func() { companyCache.companies = nil })
+
+	tests := []struct {
+		name string
+		want string // empty when nothing should match
+	}{
+		{"阿里巴巴", "阿里巴巴"},
+		{"淘宝", "阿里巴巴"},
+		{"阿里巴巴(中国)网络技术有限公司", "阿里巴巴"},
+		{"淘宝（中国）软件有限公司", "阿里巴巴"},
+		{"腾讯科技(深圳)有限公司", "腾讯"},
+		{"Tencent (America)", "腾讯"},
+		{"JD.com", "京东"},
+		{"阿里山茶业有限公司", ""},
+		{"淘宝心选科技", ""},
+		{"微信派广告传媒", ""},
+		{"jdbc consulting", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if c := ResolveCompany(tt.name); c != nil {
+				got = c.Name
+			}
+			if got != tt.want {
+				t.Errorf("ResolveCompany(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompanyIDs(t *testing.T) {
+	openTestDB(t)
+	exec(t, "DELETE FROM companies") // 去掉内置的公司库
+	companies := []*Company{{Name: "甲"}, {Name: "乙"}}
+	for _, c := range companies {
+		if err := db.Create(c).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []string{"1 OR 1=1", "1; DROP TABLE companies", "abc", "0", "-1", "99"} {
+		if _, err := GetCompany(id); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("GetCompany(%q) = %v, want not found", id, err)
+		}
+		if err := DeleteCompany(id); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("DeleteCompany(%q) = %v, want not found", id, err)
+		}
+	}
+	var count int64
+	db.Model(&Company{}).Count(&count)
+	if count != 2 {
+		t.Fatalf("%d companies left, want 2", count)
+	}
+	if err := DeleteCompany(fmt.Sprint(companies[1].ID)); err != nil {
+		t.Fatalf("DeleteCompany: %v", err)
+	}
+}
@@ -1,20 +1,82 @@
 package db
 
 import (
-	"log"
+	"fmt"
+	"strconv"
+	"strings"
 
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 var db *gorm.DB
 
-func init() {
-	var err error
-	db, err = gorm.Open(sqlite.Open("talents.db"), &gorm.Config{})
+// 支持的数据库驱动
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+)
+
+// Open connects to the database. driver is sqlite, postgres or mysql and dsn is the file
+// path for sqlite or the connection string of the server.
+func Open(driver string, dsn string) error {
+	var dialector gorm.Dialector
+	switch driver {
+	case DriverSQLite:
+		dialector = sqlite.Open(dsn)
+	case DriverPostgres:
+		dialector = postgres.Open(dsn)
+	case DriverMySQL:
+		// 时间字段需要 parseTime 才能扫描为 time.Time
+		if !strings.Contains(dsn, "parseTime=") {
+			if strings.Contains(dsn, "?") {
+				dsn += "&parseTime=true"
+			} else {
+				dsn += "?parseTime=true"
+			}
+		}
+		dialector = mysql.Open(dsn)
+	default:
+		return fmt.Errorf("unsupported database driver %q", driver)
+	}
+	conn, err := gorm.Open(dialector, &gorm.Config{})
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
-		panic("failed to connect database")
+		return fmt.Errorf("connect to %s database: %w", driver, err)
+	}
+	db = conn
+	return nil
+}
+
+// parseID parses an id taken from a request path. gorm turns a non-numeric string condition
+// into raw SQL, so anything but a positive integer is reported as not found instead.
+func parseID(id string) (uint64, error) {
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || n == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return n, nil
+}
+
+// Start brings the schema up to the latest version and loads the cached configuration. It
+// fails on a schema newer than this build.
+func Start() error {
+	if err := Migrate(LatestVersion(), false); err != nil {
+		return fmt.Errorf("migrate database: %w", err)
+	}
+	if err := loadWeightProfiles(); err != nil {
+		return fmt.Errorf("load weight profiles: %w", err)
+	}
+	if err := seedCompanies(); err != nil {
+		return fmt.Errorf("seed companies: %w", err)
+	}
+	if err := loadCompanies(); err != nil {
+		return fmt.Errorf("load companies: %w", err)
+	}
+	if err := loadUniversityRanking(); err != nil {
+		return fmt.Errorf("load university ranking: %w", err)
 	}
-	db.AutoMigrate(&Talent{})
+	return nil
 }
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// openEmptyTestDB points the package at a new, empty sqlite file
+func openEmptyTestDB(t *testing.T) {
+	t.Helper()
+	if err := Open(DriverSQLite, filepath.Join(t.TempDir(), "talents.db")); err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() {
+		if conn, err := db.DB(); err == nil {
+			conn.Close()
+		}
+	})
+}
+
+// openTestDB points the package at a fresh sqlite file migrated to the latest schema
+func openTestDB(t *testing.T) {
+	t.Helper()
+	openEmptyTestDB(t)
+	if err := Migrate(LatestVersion(), false); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+}
+
+// exec runs raw statements, failing the test on the first error
+func exec(t *testing.T, statements ...string) {
+	t.Helper()
+	for _, s := range statements {
+		if err := db.Exec(s).Error; err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+	}
+}
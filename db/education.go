@@ -0,0 +1,69 @@
+package db
+
+import (
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// EducationRecord 一段教育经历，日期格式为 YYYY-MM，FullTime 为空表示未注明，按全日制处理
+type EducationRecord struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	TalentID  uint64 `gorm:"index" json:"talentId"`
+	School    string `json:"school"`
+	Degree    string `json:"degree"` // 大专、本科、硕士、博士
+	Major     string `json:"major"`
+	StartDate string `json:"start"`
+	EndDate   string `json:"end"`
+	FullTime  *bool  `json:"fullTime"`
+}
+
+// IsFullTime reports whether the education is full-time, treating an unknown mode as full-time
+func (e *EducationRecord) IsFullTime() bool {
+	return e.FullTime == nil || *e.FullTime
+}
+
+// IsGraduate reports whether the degree is a master's or doctorate
+func (e *EducationRecord) IsGraduate() bool {
+	return educationRank(e.Degree) >= educationRank("硕士")
+}
+
+// NormalizeEducations derives the talent's Universities, highest Education and Major
+// from its education history
+func (this *Talent) NormalizeEducations() {
+	if len(this.Educations) == 0 {
+		return
+	}
+	universities := append(StringSlice{}, this.Universities...)
+	var highest *EducationRecord
+	for _, e := range this.Educations {
+		if e.School != "" && !slices.Contains(universities, e.School) {
+			universities = append(universities, e.School)
+		}
+		if educationRank(e.Degree) >= 0 && (highest == nil || educationRank(e.Degree) > educationRank(highest.Degree)) {
+			highest = e
+		}
+	}
+	this.Universities = universities
+	if highest != nil && educationRank(highest.Degree) > educationRank(this.Education) {
+		this.Education = highest.Degree
+		if highest.Major != "" {
+			this.Major = highest.Major
+		}
+	}
+}
+
+// replaceEducations replaces the education history of a talent
+func replaceEducations(tx *gorm.DB, talentID uint64, educations []*EducationRecord) error {
+	if err := tx.Delete(&EducationRecord{}, "talent_id = ?", talentID).Error; err != nil {
+		return err
+	}
+	if len(educations) == 0 {
+		return nil
+	}
+	for _, e := range educations {
+		e.ID = 0
+		e.TalentID = talentID
+	}
+	return tx.Create(&educations).Error
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeEducations(t *testing.T) {
+	partTime := false
+	tests := []struct {
+		name         string
+		talent       Talent
+		universities StringSlice
+		education    string
+		major        string
+	}{
+		{
+			name:         "no history keeps the flat fields",
+			talent:       Talent{Universities: StringSlice{"浙江大学"}, Education: "本科", Major: "软件工程"},
+			universities: StringSlice{"浙江大学"},
+			education:    "本科",
+			major:        "软件工程",
+		},
+		{
+			name: "highest degree and its major",
+			talent: Talent{Education: "本科", Major: "软件工程", Educations: []*EducationRecord{
+				{School: "浙江大学", Degree: "本科", Major: "软件工程"},
+				{School: "清华大学", Degree: "硕士", Major: "计算机"},
+			}},
+			universities: StringSlice{"浙江大学", "清华大学"},
+			education:    "硕士",
+			major:        "计算机",
+		},
+		{
+			name: "schools merged without duplicates",
+			talent: Talent{Universities: StringSlice{"浙江大学"}, Educations: []*EducationRecord{
+				{School: "浙江大学", Degree: "本科"},
+				{School: "", Degree: "硕士", FullTime: &partTime},
+			}},
+			universities: StringSlice{"浙江大学"},
+			education:    "硕士",
+		},
+		{
+			name: "lower or unknown degrees keep the stated education",
+			talent: Talent{Education: "硕士", Major: "计算机", Educations: []*EducationRecord{
+				{School: "浙江大学", Degree: "本科", Major: "数学"},
+				{School: "培训学校", Degree: "结业", Major: "设计"},
+			}},
+			universities: StringSlice{"浙江大学", "培训学校"},
+			education:    "硕士",
+			major:        "计算机",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.talent.NormalizeEducations()
+			if !slices.Equal(tt.talent.Universities, tt.universities) {
+				t.Errorf("universities = %v, want %v", tt.talent.Universities, tt.universities)
+			}
+			if tt.talent.Education != tt.education || tt.talent.Major != tt.major {
+				t.Errorf("education = %q/%q, want %q/%q", tt.talent.Education, tt.talent.Major, tt.education, tt.major)
+			}
+		})
+	}
+}
+
+func TestEducationRecordMode(t *testing.T) {
+	partTime, fullTime := false, true
+	tests := []struct {
+		record   EducationRecord
+		fullTime bool
+		graduate bool
+	}{
+		{EducationRecord{Degree: "本科"}, true, false},
+		{EducationRecord{Degree: "硕士", FullTime: &partTime}, false, true},
+		{EducationRecord{Degree: "博士", FullTime: &fullTime}, true, true},
+		{EducationRecord{Degree: "大专"}, true, false},
+	}
+	for _, tt := range tests {
+		if got := tt.record.IsFullTime(); got != tt.fullTime {
+			t.Errorf("%s IsFullTime = %v, want %v", tt.record.Degree, got, tt.fullTime)
+		}
+		if got := tt.record.IsGraduate(); got != tt.graduate {
+			t.Errorf("%s IsGraduate = %v, want %v", tt.record.Degree, got, tt.graduate)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Employment 一段工作经历，日期格式为 YYYY-MM，EndDate 为空或“至今”表示在职
+type Employment struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	TalentID    uint64 `gorm:"index" json:"talentId"`
+	Company     string `json:"company"`
+	Title       string `json:"title"`
+	StartDate   string `json:"start"`
+	EndDate     string `json:"end"`
+	Description string `json:"description"`
+}
+
+var monthPattern = regexp.MustCompile(`(\d{4})\D*(\d{1,2})?`)
+
+// parseMonth parses dates like 2020-03, 2020.3, 2020/03, 2020年3月 or 2020 into a month
+// count since year 0. Ongoing markers such as 至今 or present yield the current month.
+func parseMonth(s string, now time.Time) (int, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "", "至今", "今", "现在", "目前", "present", "now", "current":
+		return now.Year()*12 + int(now.Month()) - 1, true
+	}
+	m := monthPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	year, _ := strconv.Atoi(m[1])
+	month := 1
+	if m[2] != "" {
+		month, _ = strconv.Atoi(m[2])
+		if month < 1 || month > 12 {
+			return 0, false
+		}
+	}
+	return year*12 + month - 1, true
+}
+
+// Months returns the length of the employment in months, counting both the first and last month
+func (e *Employment) Months(now time.Time) int {
+	start, ok := parseMonth(e.StartDate, now)
+	if !ok || e.StartDate == "" {
+		return 0
+	}
+	end, ok := parseMonth(e.EndDate, now)
+	if !ok || end < start {
+		return 0
+	}
+	return end - start + 1
+}
+
+// EmploymentMonths returns the total months worked, counting overlapping employments once
+func EmploymentMonths(employments []*Employment, now time.Time) int {
+	type span struct{ start, end int }
+	spans := make([]span, 0, len(employments))
+	for _, e := range employments {
+		if months := e.Months(now); months > 0 {
+			start, _ := parseMonth(e.StartDate, now)
+			spans = append(spans, span{start, start + months - 1})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	total := 0
+	cur := span{-1, -2}
+	for _, s := range spans {
+		if s.start > cur.end+1 {
+			total += cur.end - cur.start + 1
+			cur = s
+			continue
+		}
+		cur.end = max(cur.end, s.end)
+	}
+	return total + cur.end - cur.start + 1
+}
+
+// NormalizeEmployments canonicalizes company names and derives the talent's Years and
+// Companies from its employment history
+func (this *Talent) NormalizeEmployments() {
+	if len(this.Employments) == 0 {
+		return
+	}
+	companies := append(StringSlice{}, this.Companies...)
+	for _, e := range this.Employments {
+		if c := ResolveCompany(e.Company); c != nil {
+			e.Company = c.Name
+		}
+		if e.Company != "" {
+			companies = append(companies, e.Company)
+		}
+	}
+	this.Companies = NormalizeCompanies(companies)
+	if months := EmploymentMonths(this.Employments, time.Now()); months > 0 {
+		this.Years = months / 12
+	}
+}
+
+// replaceEmployments replaces the employment history of a talent
+func replaceEmployments(tx *gorm.DB, talentID uint64, employments []*Employment) error {
+	if err := tx.Delete(&Employment{}, "talent_id = ?", talentID).Error; err != nil {
+		return err
+	}
+	if len(employments) == 0 {
+		return nil
+	}
+	for _, e := range employments {
+		e.ID = 0
+		e.TalentID = talentID
+	}
+	return tx.Create(&employments).Error
+}
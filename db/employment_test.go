@@ -0,0 +1,51 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEmploymentMonths(t *testing.T) {
+	now := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		employments []*Employment
+		want        int
+	}{
+		{"none", nil, 0},
+		{"single", []*Employment{{StartDate: "2020-01", EndDate: "2020-12"}}, 12},
+		{"same month", []*Employment{{StartDate: "2020-03", EndDate: "2020-03"}}, 1},
+		{"ongoing", []*Employment{{StartDate: "2024-07", EndDate: "至今"}}, 12},
+		{"empty end is ongoing", []*Employment{{StartDate: "2025-01"}}, 6},
+		{"date formats", []*Employment{{StartDate: "2019.3", EndDate: "2019年8月"}}, 6},
+		{"year only", []*Employment{{StartDate: "2018", EndDate: "2018-06"}}, 6},
+		{"overlap counted once", []*Employment{
+			{StartDate: "2020-01", EndDate: "2020-12"},
+			{StartDate: "2020-07", EndDate: "2021-06"},
+		}, 18},
+		{"adjacent", []*Employment{
+			{StartDate: "2020-01", EndDate: "2020-06"},
+			{StartDate: "2020-07", EndDate: "2020-12"},
+		}, 12},
+		{"gap", []*Employment{
+			{StartDate: "2021-01", EndDate: "2021-03"},
+			{StartDate: "2020-01", EndDate: "2020-03"},
+		}, 6},
+		{"contained", []*Employment{
+			{StartDate: "2020-01", EndDate: "2021-12"},
+			{StartDate: "2020-05", EndDate: "2020-08"},
+		}, 24},
+		{"invalid dates ignored", []*Employment{
+			{StartDate: "", EndDate: "2020-01"},
+			{StartDate: "2020-13", EndDate: "2021-01"},
+			{StartDate: "2021-05", EndDate: "2021-01"},
+		}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EmploymentMonths(tt.employments, now); got != tt.want {
+				t.Errorf("EmploymentMonths = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package db
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 面试结论
+const (
+	VerdictPass = "通过"
+	VerdictHold = "待定"
+	VerdictFail = "不通过"
+)
+
+// Ratings 各考察维度的评分，0-10 分，如 {"技术深度": 8, "沟通": 7}
+type Ratings map[string]float32
+
+// Scan implements the sql.Scanner interface
+func (r *Ratings) Scan(value interface{}) error {
+	return scanJSON(value, r, "Ratings")
+}
+
+// Value implements the driver.Valuer interface
+func (r Ratings) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return valueJSON(r)
+}
+
+// Interview 一轮面试。Interviewer 为面试官的 uid，Verdict 为空表示尚未给出结论
+type Interview struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	TalentID    uint64     `gorm:"index" json:"talentId"`
+	Round       int        `json:"round"` // 第几轮面试，从 1 开始
+	Interviewer uint       `json:"interviewer"`
+	ScheduledAt *time.Time `json:"scheduledAt"` // 预约时间
+	HeldAt      *time.Time `json:"heldAt"`      // 实际面试时间
+	Ratings     Ratings    `gorm:"type:text" json:"ratings"`
+	Verdict     string     `json:"verdict"`
+	Notes       string     `gorm:"type:text" json:"notes"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// Validate checks the round, verdict and ratings of an interview
+func (i *Interview) Validate() error {
+	if i.Round < 1 {
+		return fmt.Errorf("invalid round %d", i.Round)
+	}
+	switch i.Verdict {
+	case "", VerdictPass, VerdictHold, VerdictFail:
+	default:
+		return fmt.Errorf("invalid verdict %q, expected %s, %s or %s", i.Verdict, VerdictPass, VerdictHold, VerdictFail)
+	}
+	for dimension, score := range i.Ratings {
+		if dimension == "" {
+			return errors.New("rating dimension is empty")
+		}
+		if score < 0 || score > 10 {
+			return fmt.Errorf("rating %s must be between 0 and 10", dimension)
+		}
+	}
+	if len(i.Notes) > 10000 {
+		return errors.New("notes too long")
+	}
+	return nil
+}
+
+// CreateInterview saves a new interview round. A zero Round becomes the round after the talent's last one.
+func CreateInterview(i *Interview) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if i.Round == 0 {
+			var last int
+			if err := tx.Model(&Interview{}).Where("talent_id = ?", i.TalentID).
+				Select("COALESCE(MAX(round), 0)").Scan(&last).Error; err != nil {
+				return err
+			}
+			i.Round = last + 1
+		}
+		if err := i.Validate(); err != nil {
+			return err
+		}
+		return tx.Create(i).Error
+	})
+}
+
+// ListInterviews returns the interviews of a talent by round
+func ListInterviews(talentID uint64) ([]*Interview, error) {
+	var interviews []*Interview
+	if err := db.Where("talent_id = ?", talentID).Order("round, id").Find(&interviews).Error; err != nil {
+		return nil, err
+	}
+	return interviews, nil
+}
+
+// GetInterview returns an interview of a talent
+func GetInterview(talentID uint64, id string) (*Interview, error) {
+	interviewID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	var i Interview
+	if err := db.Where("talent_id = ?", talentID).First(&i, interviewID).Error; err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// UpdateInterview saves every editable field of an interview, the talent and interviewer stay unchanged
+func UpdateInterview(i *Interview) error {
+	if err := i.Validate(); err != nil {
+		return err
+	}
+	return db.Model(i).Select("round", "scheduled_at", "held_at", "ratings", "verdict", "notes").Updates(i).Error
+}
+
+func DeleteInterview(talentID uint64, id string) error {
+	interviewID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	result := db.Where("talent_id = ?", talentID).Delete(&Interview{}, interviewID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SaveFirstInterviewNotes sets the notes of the talent's first interview round, creating it if needed.
+// It backs the legacy single interview record.
+func SaveFirstInterviewNotes(talentID uint64, notes string, uid uint) (*Interview, error) {
+	var i Interview
+	err := db.Where("talent_id = ?", talentID).Order("round, id").Limit(1).Find(&i).Error
+	if err != nil {
+		return nil, err
+	}
+	if i.ID == 0 {
+		i = Interview{TalentID: talentID, Round: 1, Interviewer: uid, Notes: notes}
+		return &i, CreateInterview(&i)
+	}
+	i.Notes = notes
+	return &i, UpdateInterview(&i)
+}
+
+// migrateInterviewRecords moves the legacy free-text interview_record of each talent into a first
+// interview round and drops the column
+func migrateInterviewRecords(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn("talents", "interview_record") {
+		return nil
+	}
+	var legacy []struct {
+		ID              uint64
+		InterviewRecord string
+	}
+	err := tx.Table("talents").Select("id", "interview_record").
+		Where("interview_record IS NOT NULL AND interview_record <> ''").Scan(&legacy).Error
+	if err != nil {
+		return err
+	}
+	for _, t := range legacy {
+		var count int64
+		if err := tx.Model(&baselineInterview{}).Where("talent_id = ?", t.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			if err := tx.Create(&baselineInterview{TalentID: t.ID, Round: 1, Notes: t.InterviewRecord}).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Exec("ALTER TABLE talents DROP COLUMN interview_record").Error
+}
+
+// restoreInterviewRecords brings back the interview_record column filled with the notes of
+// each talent's first interview round
+func restoreInterviewRecords(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn("talents", "interview_record") {
+		if err := tx.Exec("ALTER TABLE talents ADD COLUMN interview_record text").Error; err != nil {
+			return err
+		}
+	}
+	return tx.Exec(`UPDATE talents SET interview_record = (SELECT notes FROM interviews
+		WHERE interviews.talent_id = talents.id ORDER BY round, id LIMIT 1)`).Error
+}
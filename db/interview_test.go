@@ -0,0 +1,38 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestInterviewIDs(t *testing.T) {
+	openTestDB(t)
+	for _, name := range []string{"甲", "乙"} {
+		talent := &Talent{Name: name}
+		if err := CreateTalent(talent, Actor{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := CreateInterview(&Interview{TalentID: talent.ID, Interviewer: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 第二个人才的面试不能通过第一个人才访问
+	for _, id := range []string{"1 OR 1=1", "0 OR 1=1", "2", "abc", "0"} {
+		if _, err := GetInterview(1, id); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("GetInterview(1, %q) = %v, want not found", id, err)
+		}
+		if err := DeleteInterview(1, id); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("DeleteInterview(1, %q) = %v, want not found", id, err)
+		}
+	}
+	var count int64
+	db.Model(&Interview{}).Count(&count)
+	if count != 2 {
+		t.Fatalf("%d interviews left, want 2", count)
+	}
+	if err := DeleteInterview(1, "1"); err != nil {
+		t.Fatalf("DeleteInterview: %v", err)
+	}
+}
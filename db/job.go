@@ -0,0 +1,195 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"talents/scoring"
+	"talents/skill"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 招聘需求状态
+const (
+	JobOpen   = "open"
+	JobPaused = "paused"
+	JobClosed = "closed"
+)
+
+// educationLevels 学历由低到高
+var educationLevels = []string{"大专", "本科", "硕士", "博士"}
+
+// educationRank returns the position of a degree in educationLevels, or -1 if unknown
+func educationRank(education string) int {
+	for i, level := range educationLevels {
+		if level == education {
+			return i
+		}
+	}
+	return -1
+}
+
+// Job 招聘需求
+type Job struct {
+	ID              uint        `gorm:"primaryKey" json:"id"`
+	Title           string      `json:"title"`
+	Department      string      `json:"department"`
+	Category        string      `gorm:"size:64;index" json:"category"` // 岗位类别，对应评分规则中的岗位，如 后端
+	RequiredSkills  StringSlice `gorm:"type:text" json:"requiredSkills"`
+	PreferredSkills StringSlice `gorm:"type:text" json:"preferredSkills"`
+	EducationFloor  string      `json:"educationFloor"` // 最低学历
+	MinYears        int         `json:"minYears"`       // 最低工作年限
+	SalaryMin       int         `json:"salaryMin"`
+	SalaryMax       int         `json:"salaryMax"`
+	Cities          StringSlice `gorm:"type:text" json:"cities"`
+	Headcount       int         `json:"headcount"`
+	Status          string      `gorm:"size:32;index" json:"status"`
+	CreatedAt       time.Time   `json:"createdAt"`
+	UpdatedAt       time.Time   `json:"updatedAt"`
+}
+
+// target 按招聘需求评分，未配置的城市和薪资沿用评分规则中的默认值
+func (j *Job) target(rules *scoring.RuleSet) scoreTarget {
+	t := scoreTarget{
+		jobID:           j.ID,
+		position:        j.Category,
+		title:           j.Title,
+		cities:          j.Cities,
+		requiredSkills:  j.RequiredSkills,
+		preferredSkills: j.PreferredSkills,
+		educationFloor:  j.EducationFloor,
+	}
+	if len(t.cities) == 0 {
+		t.cities = rules.Intent.Cities
+	}
+	if j.SalaryMax > 0 {
+		t.salaryBand = &scoring.SalaryBand{Min: j.SalaryMin, Max: j.SalaryMax}
+	} else if band, ok := rules.Intent.SalaryBands[j.Category]; ok {
+		t.salaryBand = &band
+	}
+	return t
+}
+
+func validateJob(j *Job) error {
+	j.Title = strings.TrimSpace(j.Title)
+	if j.Title == "" {
+		return errors.New("job title is required")
+	}
+	if j.Status == "" {
+		j.Status = JobOpen
+	}
+	if j.Status != JobOpen && j.Status != JobPaused && j.Status != JobClosed {
+		return fmt.Errorf("invalid job status %q", j.Status)
+	}
+	if j.EducationFloor != "" && educationRank(j.EducationFloor) == -1 {
+		return fmt.Errorf("invalid education floor %q, expected one of %v", j.EducationFloor, educationLevels)
+	}
+	if j.SalaryMin < 0 || j.SalaryMax < 0 || (j.SalaryMax > 0 && j.SalaryMin > j.SalaryMax) {
+		return fmt.Errorf("invalid salary band [%d, %d]", j.SalaryMin, j.SalaryMax)
+	}
+	if j.Headcount < 0 || j.MinYears < 0 {
+		return errors.New("headcount and minYears must not be negative")
+	}
+	j.RequiredSkills = skill.Normalize(j.RequiredSkills)
+	j.PreferredSkills = skill.Normalize(j.PreferredSkills)
+	return nil
+}
+
+func CreateJob(j *Job) error {
+	if err := validateJob(j); err != nil {
+		return err
+	}
+	return db.Create(j).Error
+}
+
+func GetJob(id string) (*Job, error) {
+	jobID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	var job Job
+	if err := db.First(&job, jobID).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func UpdateJob(j *Job) error {
+	if err := validateJob(j); err != nil {
+		return err
+	}
+	return db.Save(j).Error
+}
+
+// DeleteJob deletes a job together with its talent links
+func DeleteJob(id string) error {
+	jobID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&TalentJob{}, "job_id = ?", jobID).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&Job{}, jobID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// ListJobs lists jobs, optionally filtered by status
+func ListJobs(status string) ([]*Job, error) {
+	var jobs []*Job
+	qry := db.Order("id")
+	if status != "" {
+		qry = qry.Where("status = ?", status)
+	}
+	if err := qry.Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// TalentJob 人才与招聘需求的关联
+type TalentJob struct {
+	TalentID  uint64    `gorm:"primaryKey" json:"talentId"`
+	JobID     uint      `gorm:"primaryKey;index" json:"jobId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func LinkTalentJob(talentID uint64, jobID uint) error {
+	return db.Where(TalentJob{TalentID: talentID, JobID: jobID}).FirstOrCreate(&TalentJob{}).Error
+}
+
+func UnlinkTalentJob(talentID uint64, jobID uint) error {
+	return db.Delete(&TalentJob{}, "talent_id = ? AND job_id = ?", talentID, jobID).Error
+}
+
+// ListTalentJobs returns the jobs a talent is linked to
+func ListTalentJobs(talentID uint64) ([]*Job, error) {
+	var jobs []*Job
+	err := db.Where("id IN (?)", db.Model(&TalentJob{}).Select("job_id").Where("talent_id = ?", talentID)).
+		Order("id").Find(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ListJobTalents returns the talents linked to a job
+func ListJobTalents(jobID uint) ([]*Talent, error) {
+	var talents []*Talent
+	err := withDetails().Where("id IN (?)", db.Model(&TalentJob{}).Select("talent_id").Where("job_id = ?", jobID)).
+		Find(&talents).Error
+	if err != nil {
+		return nil, err
+	}
+	return talents, nil
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"talents/scoring"
+	"talents/skill"
+	"talents/utils"
+)
+
+// MatchCriterion 一项岗位要求的匹配情况，Ratio 为满足程度（0-1）
+type MatchCriterion struct {
+	Name        string  `json:"name"`
+	Requirement string  `json:"requirement"`
+	Actual      string  `json:"actual"`
+	Ratio       float32 `json:"ratio"`
+	Weight      float32 `json:"weight"`
+}
+
+// JobMatch 人才与招聘需求的匹配结果，Score 为 0-10 的匹配度
+type JobMatch struct {
+	Talent   *Talent          `json:"talent"`
+	Score    float32          `json:"score"`
+	Matched  []string         `json:"matched"`
+	Missing  []string         `json:"missing"`
+	Criteria []MatchCriterion `json:"criteria"`
+}
+
+func (m *JobMatch) add(c MatchCriterion) {
+	m.Criteria = append(m.Criteria, c)
+}
+
+// MatchJob scores how well a talent meets a job's requirements
+func (this *Talent) MatchJob(job *Job) *JobMatch {
+	rules := scoring.Active()
+	w := rules.Jobs.Match
+	m := &JobMatch{Talent: this, Matched: []string{}, Missing: []string{}}
+
+	skillCoverage := func(name string, terms []string, weight float32) {
+		if len(terms) == 0 {
+			m.add(MatchCriterion{Name: name, Requirement: "无", Ratio: 1, Weight: weight})
+			return
+		}
+		var have []string
+		for _, term := range terms {
+			if matched, ok := skill.Match(this.Skills, term); ok {
+				have = append(have, matched)
+				m.Matched = append(m.Matched, name+": "+term)
+			} else {
+				m.Missing = append(m.Missing, name+": "+term)
+			}
+		}
+		m.add(MatchCriterion{
+			Name:        name,
+			Requirement: strings.Join(terms, "、"),
+			Actual:      strings.Join(have, "、"),
+			Ratio:       float32(len(have)) / float32(len(terms)),
+			Weight:      weight,
+		})
+	}
+	skillCoverage("必备技能", job.RequiredSkills, w.RequiredSkills)
+	skillCoverage("加分技能", job.PreferredSkills, w.PreferredSkills)
+
+	years := MatchCriterion{Name: "工作年限", Actual: fmt.Sprintf("%d 年", this.Years), Ratio: 1, Weight: w.Years}
+	if job.MinYears > 0 {
+		years.Requirement = fmt.Sprintf(">= %d 年", job.MinYears)
+		years.Ratio = min(float32(this.Years)/float32(job.MinYears), 1)
+		if this.Years >= job.MinYears {
+			m.Matched = append(m.Matched, "工作年限: "+years.Requirement)
+		} else {
+			m.Missing = append(m.Missing, "工作年限: "+years.Requirement)
+		}
+	}
+	m.add(years)
+
+	education := MatchCriterion{Name: "学历", Actual: this.Education, Ratio: 1, Weight: w.Education}
+	if job.EducationFloor != "" {
+		education.Requirement = job.EducationFloor + "及以上"
+		if educationRank(this.Education) >= educationRank(job.EducationFloor) {
+			m.Matched = append(m.Matched, "学历: "+education.Requirement)
+		} else {
+			education.Ratio = 0
+			m.Missing = append(m.Missing, "学历: "+education.Requirement)
+		}
+	}
+	m.add(education)
+
+	city := MatchCriterion{Name: "城市", Actual: strings.Join(this.ExpectCities, "、"), Ratio: 1, Weight: w.City}
+	if len(job.Cities) > 0 {
+		city.Requirement = strings.Join(job.Cities, "、")
+		// 未填写期望城市视为接受
+		if len(this.ExpectCities) == 0 || utils.StringSliceContainsAny(job.Cities, this.ExpectCities...) {
+			m.Matched = append(m.Matched, "城市: "+city.Requirement)
+		} else {
+			city.Ratio = 0
+			m.Missing = append(m.Missing, "城市: "+city.Requirement)
+		}
+	}
+	m.add(city)
+
+	salary := MatchCriterion{Name: "薪资", Ratio: 1, Weight: w.Salary}
+	if this.ExpectSalary > 0 {
+		salary.Actual = fmt.Sprintf("%d", this.ExpectSalary)
+	}
+	if job.SalaryMax > 0 {
+		salary.Requirement = fmt.Sprintf("%d-%d", job.SalaryMin, job.SalaryMax)
+		switch {
+		case this.ExpectSalary <= job.SalaryMax:
+			m.Matched = append(m.Matched, "薪资: "+salary.Requirement)
+		case float32(this.ExpectSalary) <= float32(job.SalaryMax)*(1+rules.Intent.SalaryTolerance):
+			salary.Ratio = 0.5
+			m.Missing = append(m.Missing, "薪资: "+salary.Requirement)
+		default:
+			salary.Ratio = 0
+			m.Missing = append(m.Missing, "薪资: "+salary.Requirement)
+		}
+	}
+	m.add(salary)
+
+	var total float32
+	for _, c := range m.Criteria {
+		total += c.Ratio * c.Weight
+	}
+	m.Score = format_score(10 * total / w.Sum())
+	return m
+}
+
+// MatchCandidates scores every talent against a job and returns them ranked by match score,
+// ties broken by average score
+func MatchCandidates(job *Job) ([]*JobMatch, error) {
+	talents, err := ListTalents()
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]*JobMatch, 0, len(talents))
+	for _, t := range talents {
+		matches = append(matches, t.MatchJob(job))
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Talent.AverageScore > matches[j].Talent.AverageScore
+	})
+	return matches, nil
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestEducationFloorPenalty(t *testing.T) {
+	job := &Job{Category: "后端", EducationFloor: "硕士"}
+	tests := []struct {
+		education string
+		penalized bool
+	}{
+		{"大专", true},
+		{"本科", true},
+		{"硕士", false},
+		{"博士", false},
+		{"", false},
+		{"研究生在读", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.education, func(t *testing.T) {
+			exp := (&Talent{Education: tt.education}).ExplainJobScore(job)
+			penalized := false
+			for _, item := range exp.Education.Items {
+				if item.Rule == "低于学历要求" {
+					penalized = true
+				}
+			}
+			if penalized != tt.penalized {
+				t.Errorf("education %q penalized = %v, want %v", tt.education, penalized, tt.penalized)
+			}
+		})
+	}
+}
+
+func TestJobIDs(t *testing.T) {
+	openTestDB(t)
+	for _, j := range []*Job{{Title: "后端工程师", Category: "后端"}, {Title: "前端工程师", Category: "前端"}} {
+		if err := CreateJob(j); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []string{"1 OR 1=1", "1%20OR%201=1", "abc", "0", "99"} {
+		if _, err := GetJob(id); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("GetJob(%q) = %v, want not found", id, err)
+		}
+		if err := DeleteJob(id); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("DeleteJob(%q) = %v, want not found", id, err)
+		}
+	}
+	jobs, err := ListJobs("")
+	if err != nil || len(jobs) != 2 {
+		t.Fatalf("ListJobs = %d jobs, %v, want 2", len(jobs), err)
+	}
+	if err := DeleteJob("1"); err != nil {
+		t.Fatalf("DeleteJob: %v", err)
+	}
+}
@@ -0,0 +1,248 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrIrreversible is returned when rolling back a migration that cannot be undone
+var ErrIrreversible = errors.New("migration cannot be rolled back")
+
+// ErrDataLoss is returned when a rollback would drop tables that hold data and dropping data
+// was not allowed
+var ErrDataLoss = errors.New("rollback would drop tables that hold data")
+
+// Migration 一次版本化的表结构变更。Down 为空表示回滚时无需处理，Irreversible 表示不能回滚，
+// Drops 为回滚时删除的表，其中有数据时需显式允许
+type Migration struct {
+	Version      int
+	Name         string
+	Up           func(tx *gorm.DB) error
+	Down         func(tx *gorm.DB) error
+	Irreversible bool
+	Drops        []string
+}
+
+// SchemaMigration 已执行的迁移
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"appliedAt"`
+}
+
+// migrations 按版本顺序排列，已发布的迁移不能修改，表结构变化需追加新的迁移。迁移只使用
+// migrate_baseline.go 中冻结的表结构副本，不使用会继续变化的模型
+var migrations = []Migration{
+	{
+		Version:      1,
+		Name:         "talent surrogate ids",
+		Up:           migrateTalentIDs,
+		Irreversible: true,
+	},
+	{
+		Version: 2,
+		Name:    "baseline schema",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AutoMigrate(baselineModels(tx.Dialector.Name())...)
+		},
+		Down: func(tx *gorm.DB) error {
+			models := baselineModels(tx.Dialector.Name())
+			slices.Reverse(models)
+			return tx.Migrator().DropTable(models...)
+		},
+		Drops: []string{"talents", "weight_profiles", "score_histories", "companies", "jobs", "talent_jobs",
+			"employments", "education_records", "university_rankings", "resumes", "interviews",
+			"stage_transitions", "comments", "audit_logs"},
+	},
+	{
+		Version: 3,
+		Name:    "resume versions backfill",
+		Up:      migrateResumes,
+	},
+	{
+		Version: 4,
+		Name:    "interview rounds from interview records",
+		Up:      migrateInterviewRecords,
+		Down:    restoreInterviewRecords,
+	},
+	{
+		Version: 5,
+		Name:    "column sizes for mysql and postgres",
+		Up:      func(tx *gorm.DB) error { return resizeColumns(tx, false) },
+		Down:    func(tx *gorm.DB) error { return resizeColumns(tx, true) },
+	},
+}
+
+// sizedColumn 第 5 版改变长度的列，sized、baseline 分别为第 5 版和第 2 版的表结构
+type sizedColumn struct {
+	sized, baseline any
+	field           string
+}
+
+// sizedColumns returns the columns version 5 resizes on a driver
+func sizedColumns(driver string) []sizedColumn {
+	company, ranking := baselineIndexed(driver)
+	return []sizedColumn{
+		{&sizedCompany{}, company, "Name"},
+		{&sizedJob{}, &baselineJob{}, "Category"},
+		{&sizedJob{}, &baselineJob{}, "Status"},
+		{&sizedResume{}, &baselineResume{}, "Hash"},
+		{&sizedTalent{}, &baselineTalent{}, "Stage"},
+		{&sizedUniversityRanking{}, ranking, "Version"},
+		{&sizedUniversityRanking{}, ranking, "Data"},
+	}
+}
+
+// resizeColumns gives the indexed and large text columns the lengths of version 5, or back
+// those of version 2 when down is true. sqlite ignores lengths and is left as is.
+func resizeColumns(tx *gorm.DB, down bool) error {
+	driver := tx.Dialector.Name()
+	if driver == DriverSQLite {
+		return nil
+	}
+	for _, c := range sizedColumns(driver) {
+		model := c.sized
+		if down {
+			model = c.baseline
+		}
+		if err := tx.Migrator().AlterColumn(model, c.field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LatestVersion returns the schema version this build expects
+func LatestVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// SchemaVersion returns the version of the newest migration applied to the database, 0 for none
+func SchemaVersion() (int, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return 0, err
+	}
+	var version int
+	if err := db.Model(&SchemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error; err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// Migrations returns the known migrations in version order
+func Migrations() []Migration {
+	return slices.Clone(migrations)
+}
+
+// AppliedMigrations returns when each applied migration ran, by version
+func AppliedMigrations() (map[int]time.Time, error) {
+	if _, err := SchemaVersion(); err != nil {
+		return nil, err
+	}
+	var applied []SchemaMigration
+	if err := db.Find(&applied).Error; err != nil {
+		return nil, err
+	}
+	times := make(map[int]time.Time, len(applied))
+	for _, m := range applied {
+		times[m.Version] = m.AppliedAt
+	}
+	return times, nil
+}
+
+// Migrate runs the up or down migrations needed to bring the schema to the target version,
+// each in its own transaction. It refuses to touch a schema newer than this build knows.
+// Before rolling anything back it checks that every step down to the target can be undone
+// and, unless dropData is true, that none of them would drop a table holding data.
+func Migrate(target int, dropData bool) error {
+	current, err := SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if current > LatestVersion() {
+		return fmt.Errorf("database schema version %d is newer than the latest known version %d, refusing to run", current, LatestVersion())
+	}
+	if target < 0 || target > LatestVersion() {
+		return fmt.Errorf("unknown schema version %d", target)
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current || m.Version > target {
+			continue
+		}
+		log.Printf("Applying migration %d: %s", m.Version, m.Name)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+		}
+	}
+
+	var rollback []Migration
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if m := migrations[i]; m.Version <= current && m.Version > target {
+			rollback = append(rollback, m)
+		}
+	}
+	if err := checkRollback(rollback, dropData); err != nil {
+		return err
+	}
+	for _, m := range rollback {
+		log.Printf("Rolling back migration %d: %s", m.Version, m.Name)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if m.Down != nil {
+				if err := m.Down(tx); err != nil {
+					return err
+				}
+			}
+			return tx.Delete(&SchemaMigration{}, m.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("rollback of migration %d (%s): %w", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+// checkRollback fails if a migration on the rollback path cannot be undone or, unless
+// dropData is true, would drop a table that holds rows
+func checkRollback(rollback []Migration, dropData bool) error {
+	for _, m := range rollback {
+		if m.Irreversible {
+			return fmt.Errorf("rollback of migration %d (%s): %w", m.Version, m.Name, ErrIrreversible)
+		}
+	}
+	if dropData {
+		return nil
+	}
+	for _, m := range rollback {
+		var filled []string
+		for _, table := range m.Drops {
+			if !db.Migrator().HasTable(table) {
+				continue
+			}
+			var found []int
+			if err := db.Table(table).Select("1").Limit(1).Find(&found).Error; err != nil {
+				return err
+			}
+			if len(found) > 0 {
+				filled = append(filled, table)
+			}
+		}
+		if len(filled) > 0 {
+			return fmt.Errorf("rollback of migration %d (%s) would drop %s: %w",
+				m.Version, m.Name, strings.Join(filled, ", "), ErrDataLoss)
+		}
+	}
+	return nil
+}
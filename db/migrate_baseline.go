@@ -0,0 +1,292 @@
+package db
+
+import (
+	"time"
+)
+
+// 以下为第 2 版基线表结构的副本，供迁移 1 至 4 使用。迁移不能依赖会继续变化的模型，
+// 这些副本与已发布的表结构保持一致，不能修改，表结构变化需追加新的迁移。JSON 列为空时存为 NULL
+
+type baselineTalent struct {
+	ID              uint64  `gorm:"primaryKey"`
+	Phone           *string `gorm:"type:varchar(32);uniqueIndex"`
+	Name            string
+	Age             int8
+	Email           string
+	Education       string
+	Major           string
+	Skills          *string `gorm:"type:text"`
+	Years           int
+	Blog            string
+	Github          string
+	Native          string
+	Universities    *string `gorm:"type:text"`
+	Companies       *string `gorm:"type:text"`
+	JobPosition     string
+	IntentPosition  string
+	ExpectCities    *string `gorm:"type:text"`
+	ExpectSalary    int
+	ExperienceScore float32
+	EducationScore  float32
+	TechnicalScore  float32
+	IntentScore     float32
+	AverageScore    float32
+	ResumePath      string
+	Hash            string
+	ScoreVersion    string
+	ResumeID        uint
+	Stage           string `gorm:"default:新简历;index"`
+	StageChangedAt  *time.Time
+	RejectReason    string
+	Tags            *string    `gorm:"type:text"`
+	OwnerID         uint       `gorm:"index"`
+	DeletedAt       *time.Time `gorm:"index"`
+
+	Employments []*baselineEmployment      `gorm:"foreignKey:TalentID"`
+	Educations  []*baselineEducationRecord `gorm:"foreignKey:TalentID"`
+	Interviews  []*baselineInterview       `gorm:"foreignKey:TalentID"`
+}
+
+func (baselineTalent) TableName() string { return "talents" }
+
+type baselineWeightProfile struct {
+	JobPosition string `gorm:"primaryKey"`
+	Experience  float32
+	Education   float32
+	Technical   float32
+	Intent      float32
+	UpdatedAt   time.Time
+	UpdatedBy   uint
+}
+
+func (baselineWeightProfile) TableName() string { return "weight_profiles" }
+
+type baselineScoreHistory struct {
+	ID              uint   `gorm:"primaryKey"`
+	TalentID        uint64 `gorm:"index"`
+	ExperienceScore float32
+	EducationScore  float32
+	TechnicalScore  float32
+	IntentScore     float32
+	AverageScore    float32
+	RuleVersion     string
+	Trigger         string
+	UserID          uint
+	CreatedAt       time.Time
+}
+
+func (baselineScoreHistory) TableName() string { return "score_histories" }
+
+type baselineCompany struct {
+	ID       uint    `gorm:"primaryKey"`
+	Name     string  `gorm:"uniqueIndex"`
+	Aliases  *string `gorm:"type:text"`
+	Tier     string
+	Industry string
+}
+
+func (baselineCompany) TableName() string { return "companies" }
+
+type baselineJob struct {
+	ID              uint `gorm:"primaryKey"`
+	Title           string
+	Department      string
+	Category        string  `gorm:"index"`
+	RequiredSkills  *string `gorm:"type:text"`
+	PreferredSkills *string `gorm:"type:text"`
+	EducationFloor  string
+	MinYears        int
+	SalaryMin       int
+	SalaryMax       int
+	Cities          *string `gorm:"type:text"`
+	Headcount       int
+	Status          string `gorm:"index"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (baselineJob) TableName() string { return "jobs" }
+
+type baselineTalentJob struct {
+	TalentID  uint64 `gorm:"primaryKey"`
+	JobID     uint   `gorm:"primaryKey;index"`
+	CreatedAt time.Time
+}
+
+func (baselineTalentJob) TableName() string { return "talent_jobs" }
+
+type baselineEmployment struct {
+	ID          uint   `gorm:"primaryKey"`
+	TalentID    uint64 `gorm:"index"`
+	Company     string
+	Title       string
+	StartDate   string
+	EndDate     string
+	Description string
+}
+
+func (baselineEmployment) TableName() string { return "employments" }
+
+type baselineEducationRecord struct {
+	ID        uint   `gorm:"primaryKey"`
+	TalentID  uint64 `gorm:"index"`
+	School    string
+	Degree    string
+	Major     string
+	StartDate string
+	EndDate   string
+	FullTime  *bool
+}
+
+func (baselineEducationRecord) TableName() string { return "education_records" }
+
+type baselineUniversityRanking struct {
+	ID        uint   `gorm:"primaryKey"`
+	Version   string `gorm:"uniqueIndex"`
+	Data      string `gorm:"type:text"`
+	Count     int
+	Active    bool
+	CreatedBy uint
+	CreatedAt time.Time
+}
+
+func (baselineUniversityRanking) TableName() string { return "university_rankings" }
+
+type baselineResume struct {
+	ID          uint   `gorm:"primaryKey"`
+	TalentID    uint64 `gorm:"index"`
+	FileName    string
+	Path        string
+	Hash        string `gorm:"index"`
+	UploadedBy  uint
+	Text        string `gorm:"type:text"`
+	ParseResult string `gorm:"type:text"`
+	CreatedAt   time.Time
+}
+
+func (baselineResume) TableName() string { return "resumes" }
+
+type baselineInterview struct {
+	ID          uint   `gorm:"primaryKey"`
+	TalentID    uint64 `gorm:"index"`
+	Round       int
+	Interviewer uint
+	ScheduledAt *time.Time
+	HeldAt      *time.Time
+	Ratings     *string `gorm:"type:text"`
+	Verdict     string
+	Notes       string `gorm:"type:text"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (baselineInterview) TableName() string { return "interviews" }
+
+type baselineStageTransition struct {
+	ID        uint   `gorm:"primaryKey"`
+	TalentID  uint64 `gorm:"index"`
+	From      string
+	To        string
+	Reason    string
+	UserID    uint
+	CreatedAt time.Time
+}
+
+func (baselineStageTransition) TableName() string { return "stage_transitions" }
+
+type baselineComment struct {
+	ID        uint   `gorm:"primaryKey"`
+	TalentID  uint64 `gorm:"index"`
+	ParentID  *uint  `gorm:"index"`
+	AuthorID  uint   `gorm:"index"`
+	Body      string `gorm:"type:text"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (baselineComment) TableName() string { return "comments" }
+
+type baselineAuditLog struct {
+	ID        uint `gorm:"primaryKey"`
+	UserID    uint `gorm:"index"`
+	Endpoint  string
+	TalentID  uint64    `gorm:"index"`
+	Changes   *string   `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (baselineAuditLog) TableName() string { return "audit_logs" }
+
+// mysql 不能对 text 列建唯一索引。第 2 版发布时只支持 sqlite，mysql 上这两张表按第 5 版的列长度建表
+
+type baselineCompanyMySQL struct {
+	ID       uint    `gorm:"primaryKey"`
+	Name     string  `gorm:"size:191;uniqueIndex"`
+	Aliases  *string `gorm:"type:text"`
+	Tier     string
+	Industry string
+}
+
+func (baselineCompanyMySQL) TableName() string { return "companies" }
+
+type baselineUniversityRankingMySQL struct {
+	ID        uint   `gorm:"primaryKey"`
+	Version   string `gorm:"size:64;uniqueIndex"`
+	Data      string `gorm:"type:text"`
+	Count     int
+	Active    bool
+	CreatedBy uint
+	CreatedAt time.Time
+}
+
+func (baselineUniversityRankingMySQL) TableName() string { return "university_rankings" }
+
+// baselineIndexed returns the version 2 companies and university_rankings tables of a driver
+func baselineIndexed(driver string) (company, ranking any) {
+	if driver == DriverMySQL {
+		return &baselineCompanyMySQL{}, &baselineUniversityRankingMySQL{}
+	}
+	return &baselineCompany{}, &baselineUniversityRanking{}
+}
+
+// baselineModels 第 2 版的全部表，按建表顺序排列
+func baselineModels(driver string) []any {
+	company, ranking := baselineIndexed(driver)
+	return []any{&baselineTalent{}, &baselineWeightProfile{}, &baselineScoreHistory{}, company, &baselineJob{},
+		&baselineTalentJob{}, &baselineEmployment{}, &baselineEducationRecord{}, ranking, &baselineResume{},
+		&baselineInterview{}, &baselineStageTransition{}, &baselineComment{}, &baselineAuditLog{}}
+}
+
+// 第 5 版给出长度的列，mysql 才能为其建索引，postgres 存为 varchar
+
+type sizedCompany struct {
+	Name string `gorm:"size:191;uniqueIndex"`
+}
+
+func (sizedCompany) TableName() string { return "companies" }
+
+type sizedJob struct {
+	Category string `gorm:"size:64;index"`
+	Status   string `gorm:"size:32;index"`
+}
+
+func (sizedJob) TableName() string { return "jobs" }
+
+type sizedResume struct {
+	Hash string `gorm:"size:64;index"`
+}
+
+func (sizedResume) TableName() string { return "resumes" }
+
+type sizedTalent struct {
+	Stage string `gorm:"size:32;default:新简历;index"`
+}
+
+func (sizedTalent) TableName() string { return "talents" }
+
+type sizedUniversityRanking struct {
+	Version string `gorm:"size:64;uniqueIndex"`
+	Data    string `gorm:"size:16777216"` // 超过 mysql text 的 64KB 上限，按长度映射为 16MB 的 mediumtext
+}
+
+func (sizedUniversityRanking) TableName() string { return "university_rankings" }
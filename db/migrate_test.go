@@ -0,0 +1,140 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestMigrate(t *testing.T) {
+	latest := LatestVersion()
+	tests := []struct {
+		name        string
+		from        int
+		seed        bool // 迁移前写入一个人才和一轮面试
+		target      int
+		dropData    bool
+		wantErr     error
+		wantVersion int
+		wantTalents bool // talents 表存在
+		wantRecord  bool // talents 表有 interview_record 列
+	}{
+		{name: "up from empty", from: 0, target: latest, wantVersion: latest, wantTalents: true},
+		{name: "up from baseline", from: 2, target: latest, wantVersion: latest, wantTalents: true},
+		{name: "down restores interview records", from: latest, seed: true, target: 3,
+			wantVersion: 3, wantTalents: true, wantRecord: true},
+		{name: "down keeps tables holding data", from: latest, seed: true, target: 1,
+			wantErr: ErrDataLoss, wantVersion: latest, wantTalents: true},
+		{name: "down drops data when allowed", from: latest, seed: true, target: 1, dropData: true,
+			wantVersion: 1},
+		{name: "down drops empty tables", from: latest, target: 1, wantVersion: 1},
+		{name: "irreversible step stops before any rollback", from: latest, seed: true, target: 0, dropData: true,
+			wantErr: ErrIrreversible, wantVersion: latest, wantTalents: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openEmptyTestDB(t)
+			if err := Migrate(tt.from, false); err != nil {
+				t.Fatalf("migrate to %d: %v", tt.from, err)
+			}
+			if tt.seed {
+				exec(t, "INSERT INTO talents (name) VALUES ('甲')",
+					"INSERT INTO interviews (talent_id, round, notes) VALUES (1, 1, '一面通过')")
+			}
+
+			err := Migrate(tt.target, tt.dropData)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Migrate(%d) = %v, want %v", tt.target, err, tt.wantErr)
+			}
+			if version, err := SchemaVersion(); err != nil || version != tt.wantVersion {
+				t.Errorf("schema version = %d, %v, want %d", version, err, tt.wantVersion)
+			}
+			if got := db.Migrator().HasTable("talents"); got != tt.wantTalents {
+				t.Errorf("talents table exists = %v, want %v", got, tt.wantTalents)
+			}
+			if got := db.Migrator().HasColumn("talents", "interview_record"); got != tt.wantRecord {
+				t.Errorf("interview_record column exists = %v, want %v", got, tt.wantRecord)
+			}
+			if tt.seed && tt.wantTalents {
+				var count int64
+				if err := db.Table("talents").Count(&count).Error; err != nil || count != 1 {
+					t.Errorf("talents = %d, %v, want the seeded talent", count, err)
+				}
+			}
+			if tt.wantRecord {
+				var record string
+				if err := db.Table("talents").Select("interview_record").Scan(&record).Error; err != nil || record != "一面通过" {
+					t.Errorf("interview_record = %q, %v, want the first round notes", record, err)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateRoundTrip(t *testing.T) {
+	openEmptyTestDB(t)
+	for _, target := range []int{LatestVersion(), 1, LatestVersion(), 3, LatestVersion()} {
+		if err := Migrate(target, false); err != nil {
+			t.Fatalf("Migrate(%d): %v", target, err)
+		}
+		if version, _ := SchemaVersion(); version != target {
+			t.Fatalf("schema version = %d, want %d", version, target)
+		}
+	}
+	// 回到最新版本后模型可以正常读写
+	talent := &Talent{Name: "甲", Tags: StringSlice{"内推"}}
+	if err := CreateTalent(talent, Actor{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetTalent("1"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSizedColumns(t *testing.T) {
+	openEmptyTestDB(t)
+	for _, driver := range []string{DriverSQLite, DriverPostgres, DriverMySQL} {
+		for _, c := range sizedColumns(driver) {
+			for _, model := range []any{c.sized, c.baseline} {
+				stmt := &gorm.Statement{DB: db}
+				if err := stmt.Parse(model); err != nil {
+					t.Fatal(err)
+				}
+				field := stmt.Schema.LookUpField(c.field)
+				if field == nil {
+					t.Errorf("%s: %T has no field %s", driver, model, c.field)
+					continue
+				}
+				if model == c.sized && field.Size == 0 {
+					t.Errorf("%s: %T.%s has no size", driver, model, c.field)
+				}
+			}
+		}
+	}
+}
+
+func TestSizedColumnTypesOnMySQL(t *testing.T) {
+	dialector := mysql.Dialector{Config: &mysql.Config{}}
+	tests := []struct {
+		model any
+		field string
+		want  string
+	}{
+		{&sizedUniversityRanking{}, "Data", "mediumtext"},
+		{&UniversityRanking{}, "Data", "mediumtext"},
+		{&sizedUniversityRanking{}, "Version", "varchar(64)"},
+		{&sizedCompany{}, "Name", "varchar(191)"},
+	}
+	openEmptyTestDB(t)
+	for _, tt := range tests {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(tt.model); err != nil {
+			t.Fatal(err)
+		}
+		if got := dialector.DataTypeOf(stmt.Schema.LookUpField(tt.field)); got != tt.want {
+			t.Errorf("%T.%s = %s, want %s", tt.model, tt.field, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 招聘流程阶段
+const (
+	StageNew          = "新简历"
+	StageScreening    = "筛选"
+	StageInterviewing = "面试中"
+	StageOffer        = "offer"
+	StageHired        = "已入职"
+	StageRejected     = "淘汰"
+)
+
+// Stages 按流程顺序排列的阶段
+var Stages = []string{StageNew, StageScreening, StageInterviewing, StageOffer, StageHired, StageRejected}
+
+// transitions 每个阶段允许进入的下一阶段。已入职为终态，淘汰的候选人可以重新进入筛选
+var transitions = map[string][]string{
+	StageNew:          {StageScreening, StageRejected},
+	StageScreening:    {StageInterviewing, StageRejected},
+	StageInterviewing: {StageOffer, StageRejected},
+	StageOffer:        {StageHired, StageRejected},
+	StageHired:        {},
+	StageRejected:     {StageScreening},
+}
+
+// ErrStageChanged is returned when the talent left the expected stage during a move
+var ErrStageChanged = errors.New("talent stage changed concurrently")
+
+// ValidStage reports whether stage is a pipeline stage
+func ValidStage(stage string) bool {
+	return slices.Contains(Stages, stage)
+}
+
+// NextStages returns the stages a talent in the given stage may move to
+func NextStages(stage string) []string {
+	return transitions[stage]
+}
+
+// CanTransition reports whether a talent may move from one stage to another
+func CanTransition(from, to string) bool {
+	return slices.Contains(transitions[from], to)
+}
+
+// StageTransition 一次阶段变更，Reason 为淘汰原因或备注
+type StageTransition struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TalentID  uint64    `gorm:"index" json:"talentId"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Reason    string    `json:"reason"`
+	UserID    uint      `json:"userId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// initStage puts a new talent at the start of the pipeline
+func (this *Talent) initStage() {
+	this.Stage = StageNew
+	this.StageChangedAt = nil
+	this.RejectReason = ""
+}
+
+// MoveTalentStage moves a talent to another stage and records the transition. Moving to 淘汰
+// requires a reason.
+func MoveTalentStage(talentID uint64, to string, reason string, actor Actor) (*StageTransition, error) {
+	if !ValidStage(to) {
+		return nil, fmt.Errorf("invalid stage %q", to)
+	}
+	if to == StageRejected && reason == "" {
+		return nil, errors.New("a reason is required to reject a candidate")
+	}
+
+	var transition *StageTransition
+	err := auditTalent(actor, talentID, func(tx *gorm.DB) error {
+		var talent Talent
+		if err := tx.Select("id", "stage").First(&talent, talentID).Error; err != nil {
+			return err
+		}
+		if !CanTransition(talent.Stage, to) {
+			return fmt.Errorf("cannot move from %s to %s", talent.Stage, to)
+		}
+
+		transition = &StageTransition{TalentID: talentID, From: talent.Stage, To: to, Reason: reason, UserID: actor.UserID}
+		if err := tx.Create(transition).Error; err != nil {
+			return err
+		}
+		rejectReason := ""
+		if to == StageRejected {
+			rejectReason = reason
+		}
+		result := tx.Model(&Talent{}).Where("id = ? AND stage = ?", talentID, talent.Stage).Updates(map[string]any{
+			"stage":            to,
+			"stage_changed_at": transition.CreatedAt,
+			"reject_reason":    rejectReason,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStageChanged
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transition, nil
+}
+
+// ListStageTransitions returns the stage history of a talent, oldest first
+func ListStageTransitions(talentID uint64) ([]*StageTransition, error) {
+	var transitions []*StageTransition
+	if err := db.Where("talent_id = ?", talentID).Order("created_at, id").Find(&transitions).Error; err != nil {
+		return nil, err
+	}
+	return transitions, nil
+}
+
+// ListTalentsByStage returns the talents in a stage, most recently moved first
+func ListTalentsByStage(stage string) ([]*Talent, error) {
+	var talents []*Talent
+	err := withDetails().Where("stage = ?", stage).
+		Order("stage_changed_at IS NULL, stage_changed_at DESC, id DESC").Find(&talents).Error
+	if err != nil {
+		return nil, err
+	}
+	return talents, nil
+}
+
+// CountTalentsByStage returns the number of talents in each stage
+func CountTalentsByStage() (map[string]int64, error) {
+	var rows []struct {
+		Stage string
+		Count int64
+	}
+	if err := db.Model(&Talent{}).Select("stage, COUNT(*) AS count").Group("stage").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(Stages))
+	for _, stage := range Stages {
+		counts[stage] = 0
+	}
+	for _, row := range rows {
+		counts[row.Stage] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestMoveTalentStage(t *testing.T) {
+	openTestDB(t)
+	talent := &Talent{Name: "甲", Stage: StageOffer}
+	if err := CreateTalent(talent, Actor{}); err != nil {
+		t.Fatal(err)
+	}
+	if talent.Stage != StageNew {
+		t.Fatalf("new talent stage = %q, want %q", talent.Stage, StageNew)
+	}
+
+	moves := []struct {
+		to      string
+		reason  string
+		wantErr bool
+	}{
+		{to: StageInterviewing, wantErr: true}, // 不能跳过筛选
+		{to: StageScreening},
+		{to: StageRejected, wantErr: true}, // 淘汰需要原因
+		{to: StageRejected, reason: "薪资不匹配"},
+		{to: StageScreening},
+		{to: StageInterviewing},
+		{to: StageOffer},
+		{to: StageHired},
+		{to: StageRejected, reason: "已入职", wantErr: true}, // 已入职为终态
+		{to: "未知", wantErr: true},
+	}
+	for _, m := range moves {
+		_, err := MoveTalentStage(talent.ID, m.to, m.reason, Actor{UserID: 1})
+		if (err != nil) != m.wantErr {
+			t.Errorf("move to %s = %v, want error %v", m.to, err, m.wantErr)
+		}
+	}
+
+	got, err := GetTalent("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Stage != StageHired || got.StageChangedAt == nil || got.RejectReason != "" {
+		t.Errorf("talent stage = %q at %v, reason %q, want %q", got.Stage, got.StageChangedAt, got.RejectReason, StageHired)
+	}
+	history, err := ListStageTransitions(talent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{StageScreening, StageRejected, StageScreening, StageInterviewing, StageOffer, StageHired}
+	if len(history) != len(want) {
+		t.Fatalf("got %d transitions, want %d", len(history), len(want))
+	}
+	for i, to := range want {
+		if history[i].To != to || history[i].UserID != 1 {
+			t.Errorf("transition %d = %s by %d, want %s by 1", i, history[i].To, history[i].UserID, to)
+		}
+	}
+	if history[1].Reason != "薪资不匹配" {
+		t.Errorf("reject reason = %q, want it recorded", history[1].Reason)
+	}
+
+	if _, err := MoveTalentStage(99, StageScreening, "", Actor{UserID: 1}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("move missing talent = %v, want not found", err)
+	}
+}
+
+func TestUpdateTalentKeepsStage(t *testing.T) {
+	openTestDB(t)
+	talent := &Talent{Name: "甲"}
+	if err := CreateTalent(talent, Actor{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MoveTalentStage(talent.ID, StageRejected, "学历不符", Actor{UserID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateTalent("1", &Talent{Name: "甲", Stage: StageHired, RejectReason: "-"}, Actor{}); err != nil {
+		t.Fatal(err)
+	}
+
+	counts, err := CountTalentsByStage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts[StageRejected] != 1 || counts[StageHired] != 0 || counts[StageNew] != 0 {
+		t.Errorf("stage counts = %v, want the talent still rejected", counts)
+	}
+	got, err := GetTalent("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.RejectReason != "学历不符" {
+		t.Errorf("reject reason = %q, want it kept", got.RejectReason)
+	}
+}
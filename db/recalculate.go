@@ -0,0 +1,323 @@
+package db
+
+import (
+	"errors"
+	"sort"
+	"talents/scoring"
+
+	"gorm.io/gorm"
+)
+
+// ErrStalePreview is returned when talents changed after a recalculation preview was made
+var ErrStalePreview = errors.New("talent scores changed since the preview was made")
+
+// ScoreChange represents the score changes for a talent
+type ScoreChange struct {
+	Talent       *Talent `json:"talent"`
+	OldAvgScore  float32 `json:"old_avg_score"`
+	NewAvgScore  float32 `json:"new_avg_score"`
+	ScoreDiff    float32 `json:"score_diff"`
+	OldExpScore  float32 `json:"old_exp_score"`
+	NewExpScore  float32 `json:"new_exp_score"`
+	OldEduScore  float32 `json:"old_edu_score"`
+	NewEduScore  float32 `json:"new_edu_score"`
+	OldTechScore float32 `json:"old_tech_score"`
+	NewTechScore float32 `json:"new_tech_score"`
+	OldIntScore  float32 `json:"old_intent_score"`
+	NewIntScore  float32 `json:"new_intent_score"`
+	OldRank      int     `json:"old_rank"` // rank within the job position, starting from 1
+	NewRank      int     `json:"new_rank"`
+
+	oldVersion string // score_version the preview started from
+}
+
+// RankChange records a talent moving within the ranking of its job position
+type RankChange struct {
+	TalentID uint64 `json:"talent_id"`
+	Phone    Phone  `json:"phone"`
+	Name     string `json:"name"`
+	OldRank  int    `json:"old_rank"`
+	NewRank  int    `json:"new_rank"`
+}
+
+// PositionImpact summarizes how a recalculation affected one job position
+type PositionImpact struct {
+	Weights       scoring.Weights `json:"weights"`
+	TotalCount    int             `json:"total_count"`
+	UpdatedCount  int             `json:"updated_count"`
+	AverageChange float32         `json:"average_change"` // signed mean over all talents of the position
+	RankChanges   []RankChange    `json:"rank_changes"`
+}
+
+// RecalculationResult contains the results of a recalculation operation
+type RecalculationResult struct {
+	TotalCount    int                        `json:"total_count"`
+	UpdatedCount  int                        `json:"updated_count"`
+	NoChangeCount int                        `json:"no_change_count"`
+	ScoreChanges  []ScoreChange              `json:"score_changes"`
+	AverageChange float32                    `json:"average_change"`
+	MaximumChange float32                    `json:"maximum_change"`
+	MaximumTalent *Talent                    `json:"maximum_talent"`
+	RuleVersion   string                     `json:"rule_version"`
+	Positions     map[string]*PositionImpact `json:"positions"`
+	TopMovers     []ScoreChange              `json:"top_movers"`
+	Applied       bool                       `json:"applied"`
+
+	// talents whose scores are unchanged but were produced by another rule version
+	versionOnly []ScoreChange
+}
+
+// topMoverCount is the number of talents listed in RecalculationResult.TopMovers
+const topMoverCount = 10
+
+func abs(num float32) float32 {
+	if num < 0 {
+		return -num
+	}
+	return num
+}
+
+// rankByPosition returns the 1-based rank of every talent within its job position
+func rankByPosition(talents []*Talent, score func(*Talent) float32) map[uint64]int {
+	groups := make(map[string][]*Talent)
+	for _, t := range talents {
+		groups[t.JobPosition] = append(groups[t.JobPosition], t)
+	}
+	ranks := make(map[uint64]int, len(talents))
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool {
+			return score(group[i]) > score(group[j])
+		})
+		for i, t := range group {
+			ranks[t.ID] = i + 1
+		}
+	}
+	return ranks
+}
+
+// PreviewRecalculation recalculates scores for all talents without writing anything
+func PreviewRecalculation() (*RecalculationResult, error) {
+	return previewRecalculation(currentWeights())
+}
+
+// previewRecalculation recalculates scores for all talents with the given position weights
+func previewRecalculation(weights weightSet) (*RecalculationResult, error) {
+	// Get all talents
+	talents, err := ListTalents()
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize result
+	result := &RecalculationResult{
+		TotalCount:   len(talents),
+		ScoreChanges: make([]ScoreChange, 0),
+		RuleVersion:  scoring.Active().Version,
+		Positions:    make(map[string]*PositionImpact),
+		TopMovers:    make([]ScoreChange, 0),
+	}
+
+	oldScores := make(map[uint64]float32, len(talents))
+	for _, talent := range talents {
+		oldScores[talent.ID] = talent.AverageScore
+	}
+	oldRanks := rankByPosition(talents, func(t *Talent) float32 { return oldScores[t.ID] })
+
+	// Track the maximum change
+	var maxChange float32 = 0
+	var maxTalent *Talent = nil
+
+	// Recalculate scores for each talent
+	for _, talent := range talents {
+		// Store original scores
+		originalAvgScore := talent.AverageScore
+		originalExpScore := talent.ExperienceScore
+		originalEduScore := talent.EducationScore
+		originalTechScore := talent.TechnicalScore
+		originalIntScore := talent.IntentScore
+		originalVersion := talent.ScoreVersion
+
+		// Recalculate scores
+		talent.calcScore(weights)
+
+		// Calculate absolute difference
+		scoreDiff := talent.AverageScore - originalAvgScore
+		absDiff := abs(scoreDiff)
+
+		impact, ok := result.Positions[talent.JobPosition]
+		if !ok {
+			impact = &PositionImpact{
+				Weights:     weights.of(talent.JobPosition, scoring.Active()),
+				RankChanges: make([]RankChange, 0),
+			}
+			result.Positions[talent.JobPosition] = impact
+		}
+		impact.TotalCount++
+		impact.AverageChange += scoreDiff
+
+		// Create a score change record
+		scoreChange := ScoreChange{
+			Talent:       talent,
+			OldAvgScore:  originalAvgScore,
+			NewAvgScore:  talent.AverageScore,
+			ScoreDiff:    scoreDiff,
+			OldExpScore:  originalExpScore,
+			NewExpScore:  talent.ExperienceScore,
+			OldEduScore:  originalEduScore,
+			NewEduScore:  talent.EducationScore,
+			OldTechScore: originalTechScore,
+			NewTechScore: talent.TechnicalScore,
+			OldIntScore:  originalIntScore,
+			NewIntScore:  talent.IntentScore,
+			oldVersion:   originalVersion,
+		}
+
+		// Only update if any score changed, using a small epsilon for float comparison
+		if absDiff > 0.001 || abs(talent.ExperienceScore-originalExpScore) > 0.001 ||
+			abs(talent.EducationScore-originalEduScore) > 0.001 ||
+			abs(talent.TechnicalScore-originalTechScore) > 0.001 ||
+			abs(talent.IntentScore-originalIntScore) > 0.001 {
+			// Add to result
+			result.ScoreChanges = append(result.ScoreChanges, scoreChange)
+			result.UpdatedCount++
+			impact.UpdatedCount++
+
+			// Update average change
+			result.AverageChange += absDiff
+
+			// Track maximum change
+			if abs(scoreDiff) > abs(maxChange) {
+				maxChange = scoreDiff
+				maxTalent = talent
+			}
+		} else {
+			// Record the rule version even if the score is unchanged
+			if talent.ScoreVersion != originalVersion {
+				result.versionOnly = append(result.versionOnly, scoreChange)
+			}
+			result.NoChangeCount++
+		}
+	}
+
+	newRanks := rankByPosition(talents, func(t *Talent) float32 { return t.AverageScore })
+	for i := range result.ScoreChanges {
+		sc := &result.ScoreChanges[i]
+		sc.OldRank = oldRanks[sc.Talent.ID]
+		sc.NewRank = newRanks[sc.Talent.ID]
+	}
+	for _, talent := range talents {
+		if oldRanks[talent.ID] != newRanks[talent.ID] {
+			impact := result.Positions[talent.JobPosition]
+			impact.RankChanges = append(impact.RankChanges, RankChange{
+				TalentID: talent.ID,
+				Phone:    talent.Phone,
+				Name:     talent.Name,
+				OldRank:  oldRanks[talent.ID],
+				NewRank:  newRanks[talent.ID],
+			})
+		}
+	}
+	for _, impact := range result.Positions {
+		impact.AverageChange = format_score(impact.AverageChange / float32(impact.TotalCount))
+		sort.Slice(impact.RankChanges, func(i, j int) bool {
+			return impact.RankChanges[i].NewRank < impact.RankChanges[j].NewRank
+		})
+	}
+
+	result.TopMovers = append(result.TopMovers, result.ScoreChanges...)
+	sort.SliceStable(result.TopMovers, func(i, j int) bool {
+		return abs(result.TopMovers[i].ScoreDiff) > abs(result.TopMovers[j].ScoreDiff)
+	})
+	if len(result.TopMovers) > topMoverCount {
+		result.TopMovers = result.TopMovers[:topMoverCount]
+	}
+
+	// Calculate final average
+	if result.UpdatedCount > 0 {
+		result.AverageChange = result.AverageChange / float32(result.UpdatedCount)
+		result.MaximumChange = maxChange
+		result.MaximumTalent = maxTalent
+	}
+
+	return result, nil
+}
+
+// scoreColumns returns the score columns of a talent for a targeted update
+func scoreColumns(t *Talent) map[string]any {
+	return map[string]any{
+		"experience_score": t.ExperienceScore,
+		"education_score":  t.EducationScore,
+		"technical_score":  t.TechnicalScore,
+		"intent_score":     t.IntentScore,
+		"average_score":    t.AverageScore,
+		"score_version":    t.ScoreVersion,
+	}
+}
+
+// applyScoreChange writes all score columns of a previewed change. It fails with
+// ErrStalePreview unless the talent still has the scores and rule version the preview
+// started from; the old scores were read from the same columns, so they compare exactly.
+func applyScoreChange(tx *gorm.DB, sc ScoreChange) error {
+	res := tx.Model(&Talent{}).
+		Where("id = ? AND COALESCE(score_version, '') = ?", sc.Talent.ID, sc.oldVersion).
+		Where("average_score = ? AND experience_score = ? AND education_score = ? AND technical_score = ? AND intent_score = ?",
+			sc.OldAvgScore, sc.OldExpScore, sc.OldEduScore, sc.OldTechScore, sc.OldIntScore).
+		Updates(scoreColumns(sc.Talent))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrStalePreview
+	}
+	return nil
+}
+
+// ApplyRecalculation writes exactly the scores of a previewed recalculation, recording a
+// score snapshot with the given trigger for every changed talent. It fails with
+// ErrStalePreview without writing anything if any talent's scores moved since the preview.
+// The audit entries of the changes are written in the same transaction.
+func ApplyRecalculation(result *RecalculationResult, trigger string, actor Actor) error {
+	if result.Applied {
+		return errors.New("recalculation has already been applied")
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return applyRecalculation(tx, result, trigger, actor)
+	})
+	if err != nil {
+		return err
+	}
+	result.Applied = true
+	return nil
+}
+
+// applyRecalculation writes the scores, snapshots and audit entries of a previewed
+// recalculation in tx
+func applyRecalculation(tx *gorm.DB, result *RecalculationResult, trigger string, actor Actor) error {
+	for _, sc := range result.ScoreChanges {
+		if err := applyScoreChange(tx, sc); err != nil {
+			return err
+		}
+		if err := recordScore(tx, sc.Talent, trigger, actor.UserID); err != nil {
+			return err
+		}
+	}
+	for _, sc := range result.versionOnly {
+		if err := applyScoreChange(tx, sc); err != nil {
+			return err
+		}
+	}
+	return recordRecalculationAudit(tx, actor, result)
+}
+
+// RecalculateAllTalentScores recalculates scores for all talents in the database,
+// recording a score snapshot with the given trigger for every talent whose score changed
+func RecalculateAllTalentScores(trigger string, actor Actor) (*RecalculationResult, error) {
+	result, err := PreviewRecalculation()
+	if err != nil {
+		return nil, err
+	}
+	if err := ApplyRecalculation(result, trigger, actor); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"errors"
+	"testing"
+)
+
+func createScoredTalent(t *testing.T, name string) *Talent {
+	t.Helper()
+	talent := &Talent{Name: name, Education: "本科", Skills: StringSlice{"go", "mysql"}, Years: 5, JobPosition: "后端"}
+	talent.CalcScore()
+	if err := CreateTalent(talent, Actor{}); err != nil {
+		t.Fatalf("create talent: %v", err)
+	}
+	return talent
+}
+
+func TestApplyRecalculation(t *testing.T) {
+	openTestDB(t)
+
+	tests := []struct {
+		name    string
+		columns map[string]any // stored before the preview
+	}{
+		{
+			name:    "sub-score changed while the average stayed the same",
+			columns: map[string]any{"experience_score": 0.5},
+		},
+		{
+			name:    "only the rule version differs",
+			columns: map[string]any{"score_version": "old"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			talent := createScoredTalent(t, tt.name)
+			if err := db.Model(&Talent{}).Where("id = ?", talent.ID).Updates(tt.columns).Error; err != nil {
+				t.Fatal(err)
+			}
+			result, err := PreviewRecalculation()
+			if err != nil {
+				t.Fatalf("preview: %v", err)
+			}
+			if err := ApplyRecalculation(result, TriggerRecalc, Actor{UserID: 1}); err != nil {
+				t.Fatalf("apply: %v", err)
+			}
+			var stored Talent
+			if err := db.First(&stored, talent.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			if stored.ExperienceScore != talent.ExperienceScore || stored.AverageScore != talent.AverageScore ||
+				stored.ScoreVersion != talent.ScoreVersion {
+				t.Errorf("stored scores %v/%v/%q, want %v/%v/%q", stored.ExperienceScore, stored.AverageScore,
+					stored.ScoreVersion, talent.ExperienceScore, talent.AverageScore, talent.ScoreVersion)
+			}
+		})
+	}
+}
+
+func TestApplyRecalculationStalePreview(t *testing.T) {
+	openTestDB(t)
+	talent := createScoredTalent(t, "stale")
+	if err := db.Model(&Talent{}).Where("id = ?", talent.ID).Update("technical_score", 0.5).Error; err != nil {
+		t.Fatal(err)
+	}
+	result, err := PreviewRecalculation()
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	// 预览之后分项又被修改，平均分不变
+	if err := db.Model(&Talent{}).Where("id = ?", talent.ID).Update("technical_score", 0.7).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := ApplyRecalculation(result, TriggerRecalc, Actor{UserID: 1}); !errors.Is(err, ErrStalePreview) {
+		t.Fatalf("apply = %v, want ErrStalePreview", err)
+	}
+}
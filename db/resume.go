@@ -0,0 +1,175 @@
+package db
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Resume 人才的一份简历。Text 为提取的文本，ParseResult 为从该简历解析出的档案（JSON）
+type Resume struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	TalentID    uint64    `gorm:"index" json:"talentId"`
+	FileName    string    `json:"fileName"`
+	Path        string    `json:"path"`
+	Hash        string    `gorm:"size:64;index" json:"hash"`
+	UploadedBy  uint      `json:"uploadedBy"`
+	Text        string    `gorm:"type:text" json:"text,omitempty"`
+	ParseResult string    `gorm:"type:text" json:"parseResult,omitempty"`
+	CreatedAt   time.Time `json:"uploadedAt"`
+}
+
+// NewResume records a parsed resume file, the talent is set when it is saved
+func NewResume(fileName, path, hash, text string, parsed *Talent, uid uint) (*Resume, error) {
+	r := &Resume{FileName: fileName, Path: path, Hash: hash, Text: text, UploadedBy: uid}
+	if err := r.SetParsed(parsed); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SetParsed stores the profile parsed from the resume
+func (r *Resume) SetParsed(parsed *Talent) error {
+	if parsed == nil {
+		r.ParseResult = ""
+		return nil
+	}
+	data, err := json.Marshal(parsed)
+	if err != nil {
+		return err
+	}
+	r.ParseResult = string(data)
+	return nil
+}
+
+// Parsed returns the profile parsed from the resume, or nil if it was never parsed
+func (r *Resume) Parsed() (*Talent, error) {
+	if r.ParseResult == "" {
+		return nil, nil
+	}
+	t := &Talent{}
+	if err := json.Unmarshal([]byte(r.ParseResult), t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// CreateTalentWithResume saves a new talent, its first resume, which drives the profile, and
+// its first score snapshot
+func CreateTalentWithResume(t *Talent, r *Resume, trigger string, actor Actor) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		t.ResumePath = r.Path
+		t.Hash = r.Hash
+		t.initNew()
+		if err := tx.Create(t).Error; err != nil {
+			return err
+		}
+		r.TalentID = t.ID
+		if err := tx.Create(r).Error; err != nil {
+			return err
+		}
+		t.ResumeID = r.ID
+		if err := tx.Model(t).Update("resume_id", r.ID).Error; err != nil {
+			return err
+		}
+		if err := recordScore(tx, t, trigger, actor.UserID); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, t.ID, nil, t)
+	})
+}
+
+// AddResume saves a new resume version of an existing talent without changing the profile
+func AddResume(talentID uint64, r *Resume) error {
+	r.ID = 0
+	r.TalentID = talentID
+	return db.Create(r).Error
+}
+
+// UpdateResumeParse stores a new extraction result of a resume
+func UpdateResumeParse(r *Resume) error {
+	return db.Model(r).Select("text", "parse_result").Updates(r).Error
+}
+
+// ListResumes returns the resume versions of a talent, newest first, without text and parse result
+func ListResumes(talentID uint64) ([]*Resume, error) {
+	var resumes []*Resume
+	err := db.Omit("text", "parse_result").Where("talent_id = ?", talentID).
+		Order("created_at DESC, id DESC").Find(&resumes).Error
+	if err != nil {
+		return nil, err
+	}
+	return resumes, nil
+}
+
+// GetResume returns a resume version of a talent
+func GetResume(talentID uint64, id string) (*Resume, error) {
+	resumeID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	var r Resume
+	if err := db.Where("talent_id = ?", talentID).First(&r, resumeID).Error; err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ApplyProfile replaces a talent's profile with one parsed from a resume and records the new
+// scores. Fields the resume leaves empty are cleared rather than kept from the old profile.
+// The talent keeps its ID, interviews and, unless it had none, its phone. When resume is not
+// nil it becomes the version that drives the profile; a resume that is not saved yet is added
+// to the talent in the same transaction.
+func ApplyProfile(talent *Talent, parsed *Talent, resume *Resume, trigger string, actor Actor) error {
+	parsed.ID = talent.ID
+	if talent.Phone != "" {
+		parsed.Phone = talent.Phone
+	}
+	parsed.ResumePath, parsed.Hash, parsed.ResumeID = talent.ResumePath, talent.Hash, talent.ResumeID
+
+	return auditTalent(actor, talent.ID, func(tx *gorm.DB) error {
+		if resume != nil {
+			if resume.ID == 0 {
+				resume.TalentID = talent.ID
+				if err := tx.Create(resume).Error; err != nil {
+					return err
+				}
+			}
+			parsed.ResumePath, parsed.Hash, parsed.ResumeID = resume.Path, resume.Hash, resume.ID
+		}
+		err := tx.Model(&Talent{}).Select("*").Omit(append(protectedColumns, clause.Associations)...).
+			Where("id = ?", talent.ID).Updates(parsed).Error
+		if err != nil {
+			return err
+		}
+		if err := replaceEmployments(tx, talent.ID, parsed.Employments); err != nil {
+			return err
+		}
+		if err := replaceEducations(tx, talent.ID, parsed.Educations); err != nil {
+			return err
+		}
+		return recordScore(tx, parsed, trigger, actor.UserID)
+	})
+}
+
+// migrateResumes backfills a resume version for talents created before resumes were versioned
+func migrateResumes(tx *gorm.DB) error {
+	var talents []*baselineTalent
+	err := tx.Select("id", "resume_path", "hash").
+		Where("resume_id = 0 AND resume_path <> ''").Find(&talents).Error
+	if err != nil {
+		return err
+	}
+	for _, t := range talents {
+		r := &baselineResume{TalentID: t.ID, Path: t.ResumePath, Hash: t.Hash}
+		if err := tx.Create(r).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(t).Update("resume_id", r.ID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"testing"
+)
+
+func TestApplyProfileClearsFieldsMissingFromResume(t *testing.T) {
+	openTestDB(t)
+	old := &Talent{Name: "甲", Phone: "13800000001", Age: 30, Blog: "https://blog.example", Github: "jia",
+		ExpectSalary: 30000, Skills: StringSlice{"go"}, JobPosition: "后端"}
+	if err := CreateTalent(old, Actor{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SetTalentTags(old.ID, []string{"内推"}, Actor{}); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed := &Talent{Name: "甲", Skills: StringSlice{"java"}, JobPosition: "后端"}
+	parsed.CalcScore()
+	if err := ApplyProfile(old, parsed, nil, TriggerReparse, Actor{UserID: 1}); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	got, err := GetTalent("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Age != 0 || got.Blog != "" || got.Github != "" || got.ExpectSalary != 0 {
+		t.Errorf("stale fields survived: age %d, blog %q, github %q, salary %d", got.Age, got.Blog, got.Github, got.ExpectSalary)
+	}
+	if len(got.Skills) != 1 || got.Skills[0] != "java" {
+		t.Errorf("skills = %v, want [java]", got.Skills)
+	}
+	if got.Phone != "13800000001" {
+		t.Errorf("phone = %q, want the old phone", got.Phone)
+	}
+	if len(got.Tags) != 1 || got.Tags[0] != "内推" || got.Stage != StageNew {
+		t.Errorf("protected columns changed: tags %v, stage %q", got.Tags, got.Stage)
+	}
+}
+
+func TestSaveResumeWithScore(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    bool // 手机号已属于现有人才
+		failScore   bool
+		wantTalents int64
+		wantResumes int64
+		wantHash    string // 现有人才生效的简历
+	}{
+		{name: "new talent", wantTalents: 1, wantResumes: 1},
+		{name: "new talent rolls back", failScore: true},
+		{name: "existing talent", existing: true, wantTalents: 1, wantResumes: 2, wantHash: "b"},
+		{name: "existing talent rolls back", existing: true, failScore: true, wantTalents: 1, wantResumes: 1, wantHash: "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openTestDB(t)
+			var current *Talent
+			if tt.existing {
+				current = &Talent{Name: "甲", Phone: "13800000001"}
+				if err := CreateTalentWithResume(current, &Resume{Path: "resumes/1_a.pdf", Hash: "a"}, TriggerUpload, Actor{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.failScore {
+				exec(t, "CREATE TRIGGER fail_score BEFORE INSERT ON score_histories BEGIN SELECT RAISE(ABORT, 'score unavailable'); END")
+			}
+
+			parsed := &Talent{Name: "甲", Phone: "13800000001"}
+			parsed.CalcScore()
+			resume := &Resume{Path: "resumes/2_b.pdf", Hash: "b"}
+			var err error
+			if tt.existing {
+				err = ApplyProfile(current, parsed, resume, TriggerUpload, Actor{UserID: 1})
+			} else {
+				err = CreateTalentWithResume(parsed, resume, TriggerUpload, Actor{UserID: 1})
+			}
+			if (err != nil) != tt.failScore {
+				t.Fatalf("save = %v, want error %v", err, tt.failScore)
+			}
+
+			// 每份生效的简历对应一条评分记录
+			var talents, resumes, scores int64
+			db.Model(&Talent{}).Count(&talents)
+			db.Model(&Resume{}).Count(&resumes)
+			db.Model(&ScoreHistory{}).Count(&scores)
+			if talents != tt.wantTalents || resumes != tt.wantResumes || scores != tt.wantResumes {
+				t.Errorf("talents = %d, resumes = %d, scores = %d, want %d, %d, %d",
+					talents, resumes, scores, tt.wantTalents, tt.wantResumes, tt.wantResumes)
+			}
+			if tt.existing {
+				got, err := GetTalent("1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Hash != tt.wantHash {
+					t.Errorf("active resume hash = %q, want %q", got.Hash, tt.wantHash)
+				}
+			}
+		})
+	}
+}
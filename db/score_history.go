@@ -0,0 +1,56 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 分数快照的触发来源
+const (
+	TriggerUpload  = "upload"
+	TriggerReparse = "reparse"
+	TriggerRecalc  = "recalc"
+	TriggerWeights = "weights"
+	TriggerRanking = "ranking" // 切换院校排名数据
+	TriggerResume  = "resume"  // 切换简历版本
+)
+
+// ScoreHistory 人才分数快照
+type ScoreHistory struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	TalentID        uint64    `gorm:"index" json:"talentId"`
+	ExperienceScore float32   `json:"experienceScore"`
+	EducationScore  float32   `json:"educationScore"`
+	TechnicalScore  float32   `json:"technicalScore"`
+	IntentScore     float32   `json:"intentScore"`
+	AverageScore    float32   `json:"averageScore"`
+	RuleVersion     string    `json:"ruleVersion"`
+	Trigger         string    `json:"trigger"`
+	UserID          uint      `json:"userId"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// recordScore saves a snapshot of the talent's current scores
+func recordScore(tx *gorm.DB, t *Talent, trigger string, uid uint) error {
+	return tx.Create(&ScoreHistory{
+		TalentID:        t.ID,
+		ExperienceScore: t.ExperienceScore,
+		EducationScore:  t.EducationScore,
+		TechnicalScore:  t.TechnicalScore,
+		IntentScore:     t.IntentScore,
+		AverageScore:    t.AverageScore,
+		RuleVersion:     t.ScoreVersion,
+		Trigger:         trigger,
+		UserID:          uid,
+	}).Error
+}
+
+// ListScoreHistory returns the score snapshots of a talent, oldest first
+func ListScoreHistory(talentID uint64) ([]*ScoreHistory, error) {
+	var history []*ScoreHistory
+	if err := db.Where("talent_id = ?", talentID).Order("created_at, id").Find(&history).Error; err != nil {
+		return nil, err
+	}
+	return history, nil
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// NormalizeTags trims tags and removes empty and duplicate ones, keeping their order
+func NormalizeTags(tags []string) StringSlice {
+	normalized := StringSlice{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// SetTalentTags replaces the tags of a talent
+func SetTalentTags(talentID uint64, tags []string, actor Actor) (StringSlice, error) {
+	normalized := NormalizeTags(tags)
+	err := auditTalent(actor, talentID, func(tx *gorm.DB) error {
+		return tx.Model(&Talent{}).Where("id = ?", talentID).Update("tags", normalized).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// SetTalentOwner assigns the recruiter responsible for a talent, 0 clears the owner
+func SetTalentOwner(talentID uint64, ownerID uint, actor Actor) error {
+	return auditTalent(actor, talentID, func(tx *gorm.DB) error {
+		return tx.Model(&Talent{}).Where("id = ?", talentID).Update("owner_id", ownerID).Error
+	})
+}
+
+// ListTags returns every tag in use with the number of talents carrying it
+func ListTags() (map[string]int, error) {
+	var tagLists []StringSlice
+	if err := db.Model(&Talent{}).Where("tags IS NOT NULL").Pluck("tags", &tagLists).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for _, tags := range tagLists {
+		for _, tag := range tags {
+			counts[tag]++
+		}
+	}
+	return counts, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s with !, to be used with ESCAPE '!'
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
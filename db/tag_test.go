@@ -0,0 +1,56 @@
+package db
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSearchTalentsByTag(t *testing.T) {
+	openTestDB(t)
+	tags := map[string][]string{
+		"甲": {"内推", `a"b\c<d>&e`},
+		"乙": {"x y", "é", `a"b`, "行\u2028分隔"},
+		"丙": {"b", "100%_!"},
+		"丁": {`b\`, "c"},
+	}
+	for _, name := range []string{"甲", "乙", "丙", "丁"} {
+		talent := &Talent{Name: name}
+		if err := CreateTalent(talent, Actor{}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := SetTalentTags(talent.ID, tags[name], Actor{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		tags []string
+		want []string
+	}{
+		{[]string{`a"b\c<d>&e`}, []string{"甲"}},
+		{[]string{"内推", `a"b\c<d>&e`}, []string{"甲"}},
+		{[]string{"x y"}, []string{"乙"}},
+		{[]string{"é"}, []string{"乙"}},
+		{[]string{"行\u2028分隔"}, []string{"乙"}},
+		{[]string{"100%_!"}, []string{"丙"}},
+		{[]string{`b\`}, []string{"丁"}},
+		{[]string{"c"}, []string{"丁"}},
+		// 只命中完整的标签，不命中其他标签中转义后的片段
+		{[]string{"b"}, []string{"丙"}},
+		{[]string{"e"}, nil},
+		{[]string{"%"}, nil},
+		{[]string{"内推", "b"}, nil},
+	}
+	for _, tt := range tests {
+		talents, err := SearchTalents(TalentFilter{Tags: tt.tags})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, talent := range talents {
+			got = append(got, talent.Name)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("SearchTalents(tags %q) = %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+}
@@ -4,8 +4,12 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
-	"talents/university"
-	"talents/utils"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // StringSlice is a custom type for handling string slice in GORM
@@ -13,12 +17,7 @@ type StringSlice []string
 
 // Scan implements the sql.Scanner interface
 func (ss *StringSlice) Scan(value interface{}) error {
-	bytes, ok := value.([]byte)
-	if !ok {
-		return errors.New("failed to unmarshal StringSlice value")
-	}
-
-	return json.Unmarshal(bytes, ss)
+	return scanJSON(value, ss, "StringSlice")
 }
 
 // Value implements the driver.Valuer interface
@@ -26,404 +25,299 @@ func (ss StringSlice) Value() (driver.Value, error) {
 	if ss == nil {
 		return nil, nil
 	}
-	return json.Marshal(ss)
+	return valueJSON(ss)
 }
 
-type Talent struct {
-	Phone           uint64      `gorm:"primaryKey" json:"phone"`
-	Name            string      `json:"name"`
-	Age             int8        `json:"age"`
-	Email           string      `json:"email"`
-	Education       string      `json:"education"`
-	Major           string      `json:"major"`
-	Skills          StringSlice `gorm:"type:text" json:"skills"`
-	Years           int         `json:"years"`
-	Blog            string      `json:"blog"`
-	Github          string      `json:"github"`
-	Native          string      `json:"native"`
-	Universities    StringSlice `gorm:"type:text" json:"universities"`
-	Companies       StringSlice `gorm:"type:text" json:"companies"`
-	JobPosition     string      `json:"jobPosition"` // 应聘岗位
-	ExpectCities    StringSlice `gorm:"type:text" json:"expectCities"`
-	ExpectSalary    int         `json:"expectSalary"`
-	ExperienceScore float32     `json:"experienceScore"` // 经验分
-	EducationScore  float32     `json:"educationScore"`  // 学历分
-	TechnicalScore  float32     `json:"technicalScore"`  // 技术分
-	IntentScore     float32     `json:"intentScore"`     // 意向分
-	AverageScore    float32     `json:"averageScore"`
-	ResumePath      string      `json:"resumePath"` // 简历文件路径
-	Hash            string      `json:"hash"`
-	InterviewRecord string      `json:"interviewRecord"` // 面试记录
+// scanJSON decodes a JSON text column into dest. sqlite and mysql return the column as
+// []byte while postgres returns a string, NULL decodes as JSON null.
+func scanJSON(value interface{}, dest interface{}, name string) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		data = []byte("null")
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("failed to unmarshal %s value %v", name, value)
+	}
+	return json.Unmarshal(data, dest)
 }
 
-func (this *Talent) CalcScore() {
-	calExperienceScore := func(companies StringSlice) float32 {
-		score := float32(5)
-		lv2s := []string{"华为", "中兴", "小米", "oppo", "vivo", "realme", "思杰", "二十八", "十四", "京东", "哔哩哔哩"}
-		if utils.StringSliceContainsAny(lv2s, companies...) {
-			score = 5.5
-		}
-		lv1s := []string{"阿里", "腾讯", "百度", "字节跳动", "甲骨文"}
-		if utils.StringSliceContainsAny(lv1s, companies...) {
-			score = 7
-		}
-		lv0s := []string{"谷歌"}
-		if utils.StringSliceContainsAny(lv0s, companies...) {
-			score = 8
-		}
-		if this.Years >= 2 {
-			score += 0.5
-		}
-		if this.Years >= 5 {
-			score += 0.5
-		}
-		if this.Years >= 7 {
-			score += 0.5
-		}
-		if this.Years >= 10 {
-			score += 0.5
-		}
-		return score
+// valueJSON encodes v as a JSON string so that it is stored as text rather than bytea
+// or blob on every driver
+func valueJSON(v interface{}) (driver.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
 	}
-	this.ExperienceScore = calExperienceScore(this.Companies)
+	return string(data), nil
+}
 
-	calEducationScore := func(universities []string, education string, major string) float32 {
-		score := university.CalcScore(universities)
-		if utils.StringSliceContainsAny([]string{"软件", "计算机", "物联网", "人工智能", "大数据", "云计算", "嵌入式", "电子信息"}, major) {
-			score += 1
-		}
-		if education == "硕士" {
-			score += 1
+// Phone 手机号，可以是境外号码。为空时存为 NULL，不参与唯一约束
+type Phone string
+
+// UnmarshalJSON accepts both numbers and strings, keeping only digits and a leading +
+func (p *Phone) UnmarshalJSON(data []byte) error {
+	var s string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
 		}
-		if education == "博士" {
-			score += 2
+	} else {
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil && string(data) != "null" {
+			return err
 		}
-		return format_score(min(score, 10))
+		s = n.String()
 	}
-	this.EducationScore = calEducationScore(this.Universities, this.Education, this.Major)
-
-	calTechnicalScore := func(skills []string, blog string, github string) float32 {
-		score := float32(5.0)
-		switch this.JobPosition {
-		case "后端":
-			if !utils.StringSliceContainsAny(skills, "python") {
-				score -= 2
-			}
-			if !utils.StringSliceContainsAny(skills, "大模型", "ollama", "vllm", "transformer", "pytorch", "numpy", "langchain") {
-				score -= 2
-			}
-
-			if utils.StringSliceContainsAny(skills, "大模型微调") {
-				score += 0.5
-			}
-			if utils.StringSliceContainsAny(skills, "rust") {
-				score += 0.5
-			}
-			if utils.StringSliceContainsAny(skills, "kubernetes", "k8s") {
-				score += 0.5
-			}
-			if utils.StringSliceContainsAny(skills, "docker") {
-				score += 0.5
-			}
-			if utils.StringSliceContainsAny(skills, "es", "elasticsearch", "elk") {
-				score += 0.5
-			}
-			if utils.StringSliceContainsAny(skills, "transformer", "numpy", "pytorch") {
-				score += 0.5
-			}
-			if utils.StringSliceContainsAny(skills, "javascript", "js", "angular", "vue", "react", "nodejs") {
-				score += 0.2
-			}
-			if utils.StringSliceContainsAny(skills, "mysql", "postgresql", "sql", "tidb") {
-				score += 0.2
-			}
-			if utils.StringSliceContainsAny(skills, "设计模式") {
-				score += 0.2
-			}
-
-			for _, skill := range []string{"java", "go", "c", "c++", "nosql", "redis", "mongodb", "prometheus", "ollama", "vllm", "fastapi", "kafka", "rabbitmq", "zookeeper", "rocketmq", "pulsar", "minio", "etcd", "langchain"} {
-				if utils.StringSliceContainsAny(skills, skill) {
-					score += 0.1
-				}
-			}
-		case "算法":
-			if !utils.StringSliceContainsAny(skills, "python") {
-				return 0.1
-			}
-			score := float32(5.0)
-			if utils.StringSliceContainsAny(skills, "transformer") {
-				score += 0.5
-			}
-			if utils.StringSliceContainsAny(skills, "pytorch") {
-				score += 0.5
-			}
-			if utils.StringSliceContainsAny(skills, "微调") {
-				score += 0.5
-			}
-		case "前端":
-			if utils.StringSliceContainsAny(skills, "vue3") {
-				score += 1
-			}
-			if utils.StringSliceContainsAny(skills, "react") {
-				score += 0.5
-			}
-			if utils.StringSliceContainsAny(skills, "angular") {
-				score += 0.5
-			}
-			if utils.StringSliceContainsAny(skills, "vite") {
-				score += 1
-			}
-			if utils.StringSliceContainsAny(skills, "git") {
-				score += 1
-			}
-			if utils.StringSliceContainsAny(skills, "typescript", "ts") {
-				score += 1
-			}
-		case "运维":
-			if !utils.StringSliceContainsAny(skills, "docker") {
-				score -= 2
-			}
-			if utils.StringSliceContainsAny(skills, "大模型") {
-				score += 1
-			}
-			if utils.StringSliceContainsAny(skills, "kubernetes", "k8s") {
-				score += 1
-			}
-			if utils.StringSliceContainsAny(skills, "shell") {
-				score += 1
-			}
-			if utils.StringSliceContainsAny(skills, "arm64") {
-				score += 1
-			}
-			if utils.StringSliceContainsAny(skills, "mysql", "redis", "postgresql", "mongodb", "elasticsearch", "es", "prometheus") {
-				score += 1
-			}
-			if utils.StringSliceContainsAny(skills, "python") {
-				score += 1
-			}
-			if utils.StringSliceContainsAny(skills, "c", "java", "python", "go", "rust") {
-				score += 0.5
-			}
-		case "嵌入式":
-			if utils.StringSliceContainsAny(skills, "c", "c++") {
-				score += 1
-			}
-			if utils.StringSliceContainsAny(skills, "arm", "stm32", "esp32", "esp8266", "bsp") {
-				score += 1
-			}
-			if utils.StringSliceContainsAny(skills, "ai") {
-				score += 1
-			}
-			if utils.StringSliceContainsAny(skills, "transformer", "embedding") {
-				score += 1
-			}
-			if utils.StringSliceContainsAny(skills, "昇腾", "寒武纪") {
-				score += 1
-			}
-			if utils.StringSliceContainsAny(skills, "量化") {
-				score += 1
-			}
-			if utils.StringSliceContainsAny(skills, "移植") {
-				score += 1
-			}
-		}
+	*p = NormalizePhone(s)
+	return nil
+}
 
-		if blog != "" {
-			score += 1
-		}
-		if github != "" {
-			score += 0.5
+// NormalizePhone strips spaces and separators from a phone number, 0 counts as no phone
+func NormalizePhone(s string) Phone {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(s) {
+		if r >= '0' && r <= '9' || r == '+' && i == 0 {
+			b.WriteRune(r)
 		}
+	}
+	if strings.Trim(b.String(), "0+") == "" {
+		return ""
+	}
+	return Phone(b.String())
+}
 
-		return min(format_score(score), 10)
+// Scan implements the sql.Scanner interface
+func (p *Phone) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*p = ""
+	case string:
+		*p = Phone(v)
+	case []byte:
+		*p = Phone(v)
+	case int64:
+		*p = Phone(fmt.Sprint(v))
+	default:
+		return fmt.Errorf("failed to scan Phone value %v", value)
 	}
-	this.TechnicalScore = calTechnicalScore(this.Skills, this.Blog, this.Github)
+	return nil
+}
 
-	// calIntentScore := func(expectCities []string) float32 {
-	// 	if len(expectCities) == 0 {
-	// 		return 8
-	// 	}
-	// 	if utils.StringSliceContainsAny(expectCities, "南京") {
-	// 		return 10
-	// 	}
-	// 	return 5
-	// }
-	// this.IntentScore = calIntentScore(this.Universities)
+// Value implements the driver.Valuer interface
+func (p Phone) Value() (driver.Value, error) {
+	if p == "" {
+		return nil, nil
+	}
+	return string(p), nil
+}
 
-	// calculateAverageScore calculates average of non-zero scores
-	calcAvgScore := func(scores ...float32) float32 {
-		total := float32(0)
-		count := 0
+type Talent struct {
+	ID              uint64         `gorm:"primaryKey" json:"id"`
+	Phone           Phone          `gorm:"type:varchar(32);uniqueIndex" json:"phone"`
+	Name            string         `json:"name"`
+	Age             int8           `json:"age"`
+	Email           string         `json:"email"`
+	Education       string         `json:"education"`
+	Major           string         `json:"major"`
+	Skills          StringSlice    `gorm:"type:text" json:"skills"`
+	Years           int            `json:"years"`
+	Blog            string         `json:"blog"`
+	Github          string         `json:"github"`
+	Native          string         `json:"native"`
+	Universities    StringSlice    `gorm:"type:text" json:"universities"`
+	Companies       StringSlice    `gorm:"type:text" json:"companies"`
+	JobPosition     string         `json:"jobPosition"`    // 应聘岗位
+	IntentPosition  string         `json:"intentPosition"` // 简历中写明的求职意向
+	ExpectCities    StringSlice    `gorm:"type:text" json:"expectCities"`
+	ExpectSalary    int            `json:"expectSalary"`
+	ExperienceScore float32        `json:"experienceScore"` // 经验分
+	EducationScore  float32        `json:"educationScore"`  // 学历分
+	TechnicalScore  float32        `json:"technicalScore"`  // 技术分
+	IntentScore     float32        `json:"intentScore"`     // 意向分
+	AverageScore    float32        `json:"averageScore"`
+	ResumePath      string         `json:"resumePath"` // 简历文件路径
+	Hash            string         `json:"hash"`
+	ScoreVersion    string         `json:"scoreVersion"`                           // 评分规则与院校排名的版本
+	ResumeID        uint           `json:"resumeId"`                               // 当前生效的简历版本
+	Stage           string         `gorm:"size:32;default:新简历;index" json:"stage"` // 招聘流程阶段
+	StageChangedAt  *time.Time     `json:"stageChangedAt"`
+	RejectReason    string         `json:"rejectReason"` // 淘汰原因
+	Tags            StringSlice    `gorm:"type:text" json:"tags"`
+	OwnerID         uint           `gorm:"index" json:"ownerId"`   // 负责的招聘专员 uid
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deletedAt"` // 移入回收站的时间
+
+	Employments []*Employment      `gorm:"foreignKey:TalentID" json:"employments"` // 工作经历
+	Educations  []*EducationRecord `gorm:"foreignKey:TalentID" json:"educations"`  // 教育经历
+	Interviews  []*Interview       `gorm:"foreignKey:TalentID" json:"interviews"`  // 面试轮次
+}
 
-		for _, score := range scores {
-			total += score
-			count++
-		}
+// initNew clears what a new talent cannot bring along from a request or a parsed resume:
+// the IDs of the talent and its records, the trash state and the pipeline stage
+func (this *Talent) initNew() {
+	this.ID = 0
+	this.DeletedAt = gorm.DeletedAt{}
+	for _, e := range this.Employments {
+		e.ID = 0
+	}
+	for _, e := range this.Educations {
+		e.ID = 0
+	}
+	for _, i := range this.Interviews {
+		i.ID = 0
+	}
+	this.initStage()
+}
 
-		if count == 0 {
-			return 0
+func CreateTalent(t *Talent, actor Actor) error {
+	t.initNew()
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(t).Error; err != nil {
+			return err
 		}
+		return recordAudit(tx, actor, t.ID, nil, t)
+	})
+}
 
-		// 计算平均值并保留一位小数
-		return format_score(total / float32(count))
-	}
-	this.AverageScore = calcAvgScore(this.ExperienceScore, this.EducationScore, this.TechnicalScore)
+// withDetails preloads the associations of a talent
+func withDetails() *gorm.DB {
+	return preloadDetails(db)
 }
 
-func CreateTalent(t *Talent) error {
-	return db.Create(t).Error
+func preloadDetails(tx *gorm.DB) *gorm.DB {
+	return tx.Preload("Employments", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("start_date DESC")
+	}).Preload("Educations", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("start_date DESC")
+	}).Preload("Interviews", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("round, id")
+	})
 }
 
 func GetTalent(id string) (*Talent, error) {
+	talentID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
 	var talent Talent
-	if err := db.First(&talent, id).Error; err != nil {
+	if err := withDetails().First(&talent, talentID).Error; err != nil {
 		return nil, err
 	}
 	return &talent, nil
 }
 
-func UpdateTalent(id string, t *Talent) error {
-	return db.Model(&Talent{}).Where("phone = ?", id).Updates(t).Error
+// GetTalentByPhone finds a talent by phone number
+func GetTalentByPhone(phone string) (*Talent, error) {
+	p := NormalizePhone(phone)
+	if p == "" {
+		return nil, errors.New("invalid phone number")
+	}
+	var talent Talent
+	if err := withDetails().Where("phone = ?", p).First(&talent).Error; err != nil {
+		return nil, err
+	}
+	return &talent, nil
 }
 
-// UpdateTalentInterviewRecord updates only the interview_record field using direct SQL
-func UpdateTalentInterviewRecord(id string, interviewRecord string) error {
-	return db.Exec("UPDATE talents SET interview_record = ? WHERE phone = ?", interviewRecord, id).Error
+// protectedColumns 只能通过 MoveTalentStage、SetTalentTags、SetTalentOwner 修改，
+// 编辑档案和重新解析简历时保持不变的列
+var protectedColumns = []string{"stage", "stage_changed_at", "reject_reason", "tags", "owner_id", "deleted_at"}
+
+// UpdateTalent updates the non-empty fields of a talent. The employment and education history
+// is replaced as a whole when t carries one.
+func UpdateTalent(id string, t *Talent, actor Actor) error {
+	talentID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	return auditTalent(actor, talentID, func(tx *gorm.DB) error {
+		err := tx.Model(&Talent{}).Omit(append(protectedColumns, clause.Associations)...).Where("id = ?", talentID).Updates(t).Error
+		if err != nil {
+			return err
+		}
+		if t.Employments != nil {
+			if err := replaceEmployments(tx, talentID, t.Employments); err != nil {
+				return err
+			}
+		}
+		if t.Educations != nil {
+			return replaceEducations(tx, talentID, t.Educations)
+		}
+		return nil
+	})
 }
 
-func DeleteTalent(id string) error {
-	return db.Delete(&Talent{}, "phone = ?", id).Error
+// DeleteTalent moves a talent to the trash, it can be restored until it is purged
+func DeleteTalent(id string, actor Actor) error {
+	talentID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	return auditTalent(actor, talentID, func(tx *gorm.DB) error {
+		result := tx.Delete(&Talent{}, talentID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
 }
 
 func ListTalents() ([]*Talent, error) {
 	var talents []*Talent
-	if err := db.Find(&talents).Error; err != nil {
+	if err := withDetails().Find(&talents).Error; err != nil {
 		return nil, err
 	}
 	return talents, nil
 }
 
-// ScoreChange represents the score changes for a talent
-type ScoreChange struct {
-	Talent       *Talent `json:"talent"`
-	OldAvgScore  float32 `json:"old_avg_score"`
-	NewAvgScore  float32 `json:"new_avg_score"`
-	ScoreDiff    float32 `json:"score_diff"`
-	OldExpScore  float32 `json:"old_exp_score"`
-	NewExpScore  float32 `json:"new_exp_score"`
-	OldEduScore  float32 `json:"old_edu_score"`
-	NewEduScore  float32 `json:"new_edu_score"`
-	OldTechScore float32 `json:"old_tech_score"`
-	NewTechScore float32 `json:"new_tech_score"`
-}
-
-// RecalculationResult contains the results of a recalculation operation
-type RecalculationResult struct {
-	TotalCount    int           `json:"total_count"`
-	UpdatedCount  int           `json:"updated_count"`
-	NoChangeCount int           `json:"no_change_count"`
-	ScoreChanges  []ScoreChange `json:"score_changes"`
-	AverageChange float32       `json:"average_change"`
-	MaximumChange float32       `json:"maximum_change"`
-	MaximumTalent *Talent       `json:"maximum_talent"`
+// TalentFilter 人才查询条件，为空的条件不参与筛选。Tags 需全部命中，OwnerID 为 0 表示未分配
+type TalentFilter struct {
+	Query       string
+	Stage       string
+	Tags        []string
+	OwnerID     *uint
+	Comment     string // 评论内容包含
+	CommentedBy *uint  // 评论过的用户
 }
 
-func abs(num float32) float32 {
-	if num < 0 {
-		return -num
+func SearchTalents(f TalentFilter) ([]*Talent, error) {
+	var talents []*Talent
+	qry := withDetails()
+	if f.Query != "" {
+		qry = qry.Where("name LIKE ? OR email LIKE ?", "%"+f.Query+"%", "%"+f.Query+"%")
 	}
-	return num
-}
-
-// RecalculateAllTalentScores recalculates scores for all talents in the database
-func RecalculateAllTalentScores() (*RecalculationResult, error) {
-	// Get all talents
-	talents, err := ListTalents()
-	if err != nil {
-		return nil, err
+	if f.Stage != "" {
+		qry = qry.Where("stage = ?", f.Stage)
 	}
-
-	// Initialize result
-	result := &RecalculationResult{
-		TotalCount:   len(talents),
-		ScoreChanges: make([]ScoreChange, 0),
+	for _, tag := range f.Tags {
+		// 标签以 JSON 数组存储，按带引号的元素匹配。元素以 [ 或 , 开头，而字符串内的引号都经过转义，
+		// 不会把 a"b 当作 b 命中
+		quoted, _ := json.Marshal(tag)
+		element := escapeLike(string(quoted))
+		qry = qry.Where("tags LIKE ? ESCAPE '!' OR tags LIKE ? ESCAPE '!'", "%["+element+"%", "%,"+element+"%")
 	}
-
-	// Track the maximum change
-	var maxChange float32 = 0
-	var maxTalent *Talent = nil
-
-	// Recalculate scores for each talent
-	for _, talent := range talents {
-		// Store original scores
-		originalAvgScore := talent.AverageScore
-		originalExpScore := talent.ExperienceScore
-		originalEduScore := talent.EducationScore
-		originalTechScore := talent.TechnicalScore
-
-		// Recalculate scores
-		talent.CalcScore()
-
-		// Calculate absolute difference
-		scoreDiff := talent.AverageScore - originalAvgScore
-		absDiff := scoreDiff
-		if absDiff < 0 {
-			absDiff = -absDiff
+	switch {
+	case f.OwnerID == nil:
+	case *f.OwnerID == 0:
+		qry = qry.Where("owner_id = 0 OR owner_id IS NULL")
+	default:
+		qry = qry.Where("owner_id = ?", *f.OwnerID)
+	}
+	if f.Comment != "" || f.CommentedBy != nil {
+		comments := db.Model(&Comment{}).Select("talent_id")
+		if f.Comment != "" {
+			comments = comments.Where("body LIKE ? ESCAPE '!'", "%"+escapeLike(f.Comment)+"%")
 		}
-
-		// Only update if score changed
-		if absDiff > 0.001 { // Use a small epsilon for float comparison
-			// Update the database
-			if err := db.Save(talent).Error; err != nil {
-				return nil, err
-			}
-
-			// Create a score change record
-			scoreChange := ScoreChange{
-				Talent:       talent,
-				OldAvgScore:  originalAvgScore,
-				NewAvgScore:  talent.AverageScore,
-				ScoreDiff:    scoreDiff,
-				OldExpScore:  originalExpScore,
-				NewExpScore:  talent.ExperienceScore,
-				OldEduScore:  originalEduScore,
-				NewEduScore:  talent.EducationScore,
-				OldTechScore: originalTechScore,
-				NewTechScore: talent.TechnicalScore,
-			}
-
-			// Add to result
-			result.ScoreChanges = append(result.ScoreChanges, scoreChange)
-			result.UpdatedCount++
-
-			// Update average change
-			result.AverageChange += absDiff
-
-			// Track maximum change
-			if abs(scoreDiff) > abs(maxChange) {
-				maxChange = scoreDiff
-				maxTalent = talent
-			}
-		} else {
-			result.NoChangeCount++
+		if f.CommentedBy != nil {
+			comments = comments.Where("author_id = ?", *f.CommentedBy)
 		}
-	}
-
-	// Calculate final average
-	if result.UpdatedCount > 0 {
-		result.AverageChange = result.AverageChange / float32(result.UpdatedCount)
-		result.MaximumChange = maxChange
-		result.MaximumTalent = maxTalent
-	}
-
-	return result, nil
-}
-
-func SearchTalents(query string) ([]*Talent, error) {
-	var talents []*Talent
-	qry := db
-	if query != "" {
-		qry = qry.Where("name LIKE ? OR email LIKE ?", "%"+query+"%", "%"+query+"%")
+		qry = qry.Where("id IN (?)", comments)
 	}
 	if err := qry.Find(&talents).Error; err != nil {
 		return nil, err
@@ -431,10 +325,12 @@ func SearchTalents(query string) ([]*Talent, error) {
 	return talents, nil
 }
 
-// GetTalentByHash checks if a talent with the given resume hash already exists
+// GetTalentByHash checks if a talent with the given resume hash already exists, in any resume version
 func GetTalentByHash(hash string) (*Talent, error) {
 	var talent Talent
-	err := db.Where("hash = ?", hash).First(&talent).Error
+	err := withDetails().
+		Where("hash = ? OR id IN (?)", hash, db.Model(&Resume{}).Select("talent_id").Where("hash = ?", hash)).
+		First(&talent).Error
 	if err != nil {
 		return nil, err
 	}
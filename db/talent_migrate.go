@@ -0,0 +1,80 @@
+package db
+
+import (
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// talentRefs 以 talent_id 引用人才的表
+var talentRefs = []string{"score_histories", "talent_jobs", "employments", "education_records"}
+
+// migrateTalentIDs converts a talents table keyed by phone into one keyed by an
+// auto-generated id. Talents keep their order, phone 0 becomes NULL and every
+// talent_id reference is rewritten from the old phone to the new id.
+func migrateTalentIDs(tx *gorm.DB) error {
+	m := tx.Migrator()
+	if !m.HasTable(&baselineTalent{}) || m.HasColumn(&baselineTalent{}, "id") {
+		return nil
+	}
+
+	return tx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().RenameTable("talents", "talents_legacy"); err != nil {
+			return err
+		}
+		if err := tx.Migrator().CreateTable(&baselineTalent{}); err != nil {
+			return err
+		}
+
+		columnTypes, err := tx.Migrator().ColumnTypes("talents_legacy")
+		if err != nil {
+			return err
+		}
+		columns := []string{}
+		for _, ct := range columnTypes {
+			name := ct.Name()
+			if name == "phone" {
+				continue
+			}
+			// 保留已不在模型中的旧列（如 interview_record），由后续迁移处理
+			if !tx.Migrator().HasColumn(&baselineTalent{}, name) {
+				if err := tx.Exec("ALTER TABLE talents ADD COLUMN " + name + " " + ct.DatabaseTypeName()).Error; err != nil {
+					return err
+				}
+			}
+			columns = append(columns, name)
+		}
+		list := strings.Join(columns, ", ")
+		err = tx.Exec("INSERT INTO talents (phone, " + list + ") SELECT NULLIF(CAST(phone AS TEXT), '0'), " + list +
+			" FROM talents_legacy ORDER BY phone").Error
+		if err != nil {
+			return err
+		}
+
+		var talents []*baselineTalent
+		if err := tx.Select("id", "phone").Find(&talents).Error; err != nil {
+			return err
+		}
+		for _, table := range talentRefs {
+			if !tx.Migrator().HasTable(table) {
+				continue
+			}
+			// 先写成负数，避免新 id 与尚未改写的旧手机号冲突
+			for _, t := range talents {
+				var old uint64
+				if t.Phone != nil {
+					old, _ = strconv.ParseUint(*t.Phone, 10, 64)
+				}
+				err := tx.Exec("UPDATE "+table+" SET talent_id = ? WHERE talent_id = ?", -int64(t.ID), old).Error
+				if err != nil {
+					return err
+				}
+			}
+			if err := tx.Exec("UPDATE " + table + " SET talent_id = -talent_id WHERE talent_id < 0").Error; err != nil {
+				return err
+			}
+		}
+		return tx.Migrator().DropTable("talents_legacy")
+	})
+}
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"testing"
+)
+
+// legacySchema 以手机号为主键的旧版数据库
+var legacySchema = []string{
+	`CREATE TABLE talents (phone integer PRIMARY KEY, name text, skills text, resume_path text, hash text,
+		average_score real, interview_record text)`,
+	`CREATE TABLE employments (id integer PRIMARY KEY AUTOINCREMENT, talent_id integer, company text)`,
+	`CREATE TABLE education_records (id integer PRIMARY KEY AUTOINCREMENT, talent_id integer, school text)`,
+	`CREATE TABLE score_histories (id integer PRIMARY KEY AUTOINCREMENT, talent_id integer, average_score real)`,
+	`CREATE TABLE talent_jobs (talent_id integer, job_id integer, PRIMARY KEY (talent_id, job_id))`,
+}
+
+func TestMigrateLegacyTalentIDs(t *testing.T) {
+	openEmptyTestDB(t)
+	exec(t, legacySchema...)
+	exec(t,
+		// 手机号与新 id 的取值范围重叠，检验改写 talent_id 时不会串号
+		`INSERT INTO talents (phone, name, skills, average_score, interview_record) VALUES
+			(13800000002, '乙', '["go"]', 7.5, '二面通过'),
+			(2, '丙', NULL, 6, ''),
+			(0, '无手机号', NULL, 5, NULL),
+			(13800000001, '甲', '["java"]', 8, NULL)`,
+		`INSERT INTO employments (talent_id, company) VALUES (13800000001, '甲公司'), (2, '丙公司')`,
+		`INSERT INTO education_records (talent_id, school) VALUES (13800000002, '乙大学')`,
+		`INSERT INTO score_histories (talent_id, average_score) VALUES (13800000001, 8), (2, 6)`,
+		`INSERT INTO talent_jobs (talent_id, job_id) VALUES (13800000002, 1), (0, 1)`,
+	)
+
+	if err := Migrate(LatestVersion(), false); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	// 按手机号排序后依次编号：0、2、13800000001、13800000002
+	want := []struct {
+		id    uint64
+		phone Phone
+		name  string
+	}{
+		{1, "", "无手机号"},
+		{2, "2", "丙"},
+		{3, "13800000001", "甲"},
+		{4, "13800000002", "乙"},
+	}
+	var talents []*Talent
+	if err := db.Order("id").Find(&talents).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(talents) != len(want) {
+		t.Fatalf("got %d talents, want %d", len(talents), len(want))
+	}
+	for i, w := range want {
+		if got := talents[i]; got.ID != w.id || got.Phone != w.phone || got.Name != w.name {
+			t.Errorf("talent %d = %d/%q/%q, want %d/%q/%q", i, got.ID, got.Phone, got.Name, w.id, w.phone, w.name)
+		}
+	}
+	if talents[3].Skills[0] != "go" || talents[3].AverageScore != 7.5 {
+		t.Errorf("columns not copied: %+v", talents[3])
+	}
+
+	refs := []struct {
+		table string
+		want  []uint64
+	}{
+		{"employments", []uint64{2, 3}},
+		{"education_records", []uint64{4}},
+		{"score_histories", []uint64{2, 3}},
+		{"talent_jobs", []uint64{1, 4}},
+		{"interviews", []uint64{4}},
+	}
+	for _, ref := range refs {
+		var ids []uint64
+		if err := db.Table(ref.table).Order("talent_id").Pluck("talent_id", &ids).Error; err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) != len(ref.want) {
+			t.Errorf("%s talent ids = %v, want %v", ref.table, ids, ref.want)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != ref.want[i] {
+				t.Errorf("%s talent ids = %v, want %v", ref.table, ids, ref.want)
+				break
+			}
+		}
+	}
+
+	var interview Interview
+	if err := db.First(&interview, "talent_id = ?", 4).Error; err != nil || interview.Round != 1 || interview.Notes != "二面通过" {
+		t.Errorf("interview record = %+v, %v, want round 1 with the legacy notes", interview, err)
+	}
+	if db.Migrator().HasColumn("talents", "interview_record") {
+		t.Error("interview_record column was not dropped")
+	}
+}
+
+func TestGetTalentIDs(t *testing.T) {
+	openTestDB(t)
+	for _, talent := range []*Talent{{Name: "甲"}, {Name: "乙"}} {
+		if err := CreateTalent(talent, Actor{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []string{"1 OR 1=1", "abc", "0", "3"} {
+		if _, err := GetTalent(id); err == nil {
+			t.Errorf("GetTalent(%q) succeeded, want not found", id)
+		}
+	}
+	if talent, err := GetTalent("2"); err != nil || talent.Name != "乙" {
+		t.Errorf("GetTalent(2) = %v, %v", talent, err)
+	}
+}
+
+func TestPurgeTalentRemovesReferences(t *testing.T) {
+	openTestDB(t)
+	talent := &Talent{Name: "甲"}
+	if err := CreateTalent(talent, Actor{}); err != nil {
+		t.Fatal(err)
+	}
+	job := &Job{Title: "后端工程师", Category: "后端"}
+	if err := CreateJob(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkTalentJob(talent.ID, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := recordScore(db, talent, TriggerUpload, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteTalent("1", Actor{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := PurgeTalent(talent.ID, Actor{}); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	for _, model := range []any{&TalentJob{}, &ScoreHistory{}} {
+		var count int64
+		db.Model(model).Where("talent_id = ?", talent.ID).Count(&count)
+		if count != 0 {
+			t.Errorf("%T: %d rows left for the purged talent", model, count)
+		}
+	}
+}
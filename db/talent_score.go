@@ -0,0 +1,419 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"talents/scoring"
+	"talents/skill"
+	"talents/university"
+	"talents/utils"
+	"time"
+)
+
+// ScoreItem 一条规则对分数的贡献
+type ScoreItem struct {
+	Rule   string  `json:"rule"`
+	Detail string  `json:"detail,omitempty"`
+	Score  float32 `json:"score"`
+}
+
+// SubScore 分项得分及其明细，Capped 表示原始分 Raw 超过了上限 Cap
+type SubScore struct {
+	Score  float32     `json:"score"`
+	Raw    float32     `json:"raw"`
+	Cap    float32     `json:"cap,omitempty"`
+	Capped bool        `json:"capped"`
+	Items  []ScoreItem `json:"items"`
+}
+
+func (s *SubScore) add(rule string, detail string, score float32) {
+	s.Items = append(s.Items, ScoreItem{Rule: rule, Detail: detail, Score: score})
+	s.Raw += score
+}
+
+// limit 按上限截断分数，cap 为 0 表示不设上限
+func (s *SubScore) limit(cap float32) {
+	s.Score = format_score(s.Raw)
+	if cap > 0 && s.Score > cap {
+		s.Cap = cap
+		s.Capped = true
+		s.Score = cap
+	}
+}
+
+// ScoreExplanation 评分明细
+type ScoreExplanation struct {
+	RuleVersion  string          `json:"ruleVersion"`
+	ScoreVersion string          `json:"scoreVersion"` // 评分规则版本+院校排名版本
+	JobPosition  string          `json:"jobPosition"`
+	JobID        uint            `json:"jobId,omitempty"`
+	Experience   SubScore        `json:"experience"`
+	Education    SubScore        `json:"education"`
+	Technical    SubScore        `json:"technical"`
+	Intent       SubScore        `json:"intent"`
+	Weights      scoring.Weights `json:"weights"`
+	AverageScore float32         `json:"averageScore"`
+}
+
+// scoreTarget 评分所针对的岗位要求，来自评分规则中的岗位或某个招聘需求
+type scoreTarget struct {
+	jobID           uint
+	position        string // 评分规则中的岗位
+	title           string
+	cities          []string
+	salaryBand      *scoring.SalaryBand
+	requiredSkills  []string
+	preferredSkills []string
+	educationFloor  string
+}
+
+// positionTarget 按应聘岗位和评分规则中的默认配置评分
+func (this *Talent) positionTarget(rules *scoring.RuleSet) scoreTarget {
+	t := scoreTarget{
+		position: this.JobPosition,
+		title:    this.JobPosition,
+		cities:   rules.Intent.Cities,
+	}
+	if band, ok := rules.Intent.SalaryBands[this.JobPosition]; ok {
+		t.salaryBand = &band
+	}
+	return t
+}
+
+// intentMatches 判断简历中的求职意向是否与目标岗位一致
+func (t scoreTarget) intentMatches(intent string) bool {
+	intent = strings.ToLower(intent)
+	for _, name := range []string{t.position, t.title} {
+		name = strings.ToLower(name)
+		if name != "" && (strings.Contains(intent, name) || strings.Contains(name, intent)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExplainScore 按当前生效的规则计算分数并给出每条规则的贡献，不修改 talent
+func (this *Talent) ExplainScore() *ScoreExplanation {
+	rules := scoring.Active()
+	return this.explainScore(rules, currentWeights(), this.positionTarget(rules))
+}
+
+// ExplainJobScore 按招聘需求的要求计算分数，不修改 talent
+func (this *Talent) ExplainJobScore(job *Job) *ScoreExplanation {
+	rules := scoring.Active()
+	return this.explainScore(rules, currentWeights(), job.target(rules))
+}
+
+func (this *Talent) explainScore(rules *scoring.RuleSet, weights weightSet, target scoreTarget) *ScoreExplanation {
+	exp := &ScoreExplanation{
+		RuleVersion:  rules.Version,
+		ScoreVersion: scoreVersion(rules),
+		JobPosition:  target.position,
+		JobID:        target.jobID,
+	}
+
+	// companyTier returns the highest tier above the base score that a company belongs to
+	companyTier := func(company string) (*scoring.CompanyTier, string) {
+		r := rules.Experience
+		var best *scoring.CompanyTier
+		name := company
+		consider := func(tier scoring.CompanyTier) {
+			if tier.Score > r.Base && (best == nil || tier.Score > best.Score) {
+				best = &tier
+			}
+		}
+		// 优先使用公司库中的档位，未收录的公司按规则中的公司名称匹配
+		if c := ResolveCompany(company); c != nil {
+			name = c.Name
+			if tier, ok := rules.Tier(c.Tier); ok {
+				consider(tier)
+			}
+			return best, name
+		}
+		for _, tier := range r.CompanyTiers {
+			if utils.StringSliceContainsAny(tier.Companies, company) {
+				consider(tier)
+			}
+		}
+		return best, name
+	}
+
+	calExperienceScore := func(companies StringSlice, employments []*Employment) SubScore {
+		r := rules.Experience
+		s := SubScore{}
+		s.add("基础分", "", r.Base)
+		if len(employments) > 0 && r.TenureFullMonths > 0 {
+			// 按任职时长折算公司档位加分，取折算后最高的一家
+			months := make(map[string]int)
+			tiers := make(map[string]*scoring.CompanyTier)
+			now := time.Now()
+			for _, e := range employments {
+				tier, name := companyTier(e.Company)
+				if tier == nil {
+					continue
+				}
+				months[name] += e.Months(now)
+				tiers[name] = tier
+			}
+			var bonus float32
+			detail := ""
+			for name, tier := range tiers {
+				b := (tier.Score - r.Base) * min(float32(months[name])/float32(r.TenureFullMonths), 1)
+				if b > bonus {
+					bonus = b
+					detail = fmt.Sprintf("%s: %s（%d 个月）", tier.Name, name, months[name])
+				}
+			}
+			if bonus > 0 {
+				s.add("公司档位", detail, bonus)
+			}
+		} else {
+			var best *scoring.CompanyTier
+			bestCompany := ""
+			for _, company := range companies {
+				if tier, name := companyTier(company); tier != nil && (best == nil || tier.Score > best.Score) {
+					best, bestCompany = tier, name
+				}
+			}
+			if best != nil {
+				s.add("公司档位", best.Name+": "+bestCompany, best.Score-r.Base)
+			}
+		}
+		for _, yb := range r.YearsBonuses {
+			if this.Years >= yb.MinYears {
+				s.add("工作年限", fmt.Sprintf(">= %d 年", yb.MinYears), yb.Bonus)
+			}
+		}
+		s.limit(0)
+		return s
+	}
+	exp.Experience = calExperienceScore(this.Companies, this.Employments)
+
+	// calSchoolScore scores undergraduate and graduate institutions separately from the
+	// education history, weighted by rules.Education.Schools
+	calSchoolScore := func(s *SubScore, educations []*EducationRecord) {
+		w := rules.Education.Schools
+		schoolScore := func(e *EducationRecord) float32 {
+			score := university.CalcScore([]string{e.School})
+			if !e.IsFullTime() {
+				score *= w.PartTimeFactor
+			}
+			return score
+		}
+		var undergrad, graduate *EducationRecord
+		var undergradScore, graduateScore float32
+		college := false
+		for _, e := range educations {
+			switch {
+			case e.IsGraduate():
+				if score := schoolScore(e); graduate == nil || score > graduateScore {
+					graduate, graduateScore = e, score
+				}
+			case e.Degree == "本科":
+				if score := schoolScore(e); undergrad == nil || score > undergradScore {
+					undergrad, undergradScore = e, score
+				}
+			case e.Degree == "大专":
+				college = true
+			}
+		}
+		if undergrad == nil && graduate == nil {
+			// 只有大专等经历时按院校最高分计
+			schools := make([]string, 0, len(educations))
+			for _, e := range educations {
+				schools = append(schools, e.School)
+			}
+			s.add("院校", strings.Join(schools, "、"), university.CalcScore(schools))
+			return
+		}
+		// 只有一个阶段的院校时按该阶段计满
+		uw, gw := w.Undergraduate, w.Graduate
+		switch {
+		case graduate == nil:
+			uw, gw = 1, 0
+		case undergrad == nil:
+			uw, gw = 0, 1
+		}
+		if undergrad != nil {
+			s.add("本科院校", undergrad.School, format_score(undergradScore*uw/(uw+gw)))
+		}
+		if graduate != nil {
+			s.add("研究生院校", graduate.Degree+": "+graduate.School, format_score(graduateScore*gw/(uw+gw)))
+		}
+		if undergrad != nil && college && w.UpgradePenalty > 0 {
+			s.add("专升本", undergrad.School, -w.UpgradePenalty)
+		}
+	}
+
+	calEducationScore := func(universities []string, education string, major string) SubScore {
+		r := rules.Education
+		s := SubScore{}
+		if len(this.Educations) > 0 {
+			calSchoolScore(&s, this.Educations)
+		} else {
+			s.add("院校", strings.Join(universities, "、"), university.CalcScore(universities))
+		}
+		schools := universities
+		if len(this.Educations) > 0 {
+			schools = make([]string, 0, len(this.Educations))
+			for _, e := range this.Educations {
+				schools = append(schools, e.School)
+			}
+		}
+		for _, b := range r.SchoolBonuses {
+			if u, ok := university.Qualifies(schools, b.Tier, b.CSRating); ok {
+				s.add("院校层次", b.Name+": "+u.UnivNameCn, b.Bonus)
+			}
+		}
+		if utils.StringSliceContainsAny(r.MajorKeywords, major) {
+			s.add("相关专业", major, r.MajorBonus)
+		}
+		if bonus, ok := r.DegreeBonuses[education]; ok {
+			s.add("学历", education, bonus)
+		}
+		// 学历未填写或无法识别时不按学历要求扣分
+		if rank := educationRank(education); target.educationFloor != "" && rank >= 0 && rank < educationRank(target.educationFloor) {
+			s.add("低于学历要求", target.educationFloor, -rules.Jobs.EducationFloorPenalty)
+		}
+		s.limit(r.Cap)
+		s.Score = max(s.Score, 0)
+		return s
+	}
+	exp.Education = calEducationScore(this.Universities, this.Education, this.Major)
+
+	calTechnicalScore := func(skills []string, blog string, github string) SubScore {
+		p := rules.Position(target.position)
+		s := SubScore{}
+		s.add("基础分", target.position, p.Base)
+		// 只有规则要求时才由下级技能命中上级分类
+		matchSkill := func(children bool, terms ...string) (string, bool) {
+			if children {
+				return skill.MatchChildren(skills, terms...)
+			}
+			return skill.Match(skills, terms...)
+		}
+		for _, r := range p.Required {
+			if _, ok := matchSkill(r.Children, r.AnyOf...); ok {
+				continue
+			}
+			if r.Reject {
+				// 缺少一票否决的技能，直接记为最低分
+				s.Items = append(s.Items, ScoreItem{Rule: "缺少必备技能（否决）", Detail: r.Name, Score: rules.Technical.RejectScore - s.Raw})
+				s.Raw = rules.Technical.RejectScore
+				s.Score = s.Raw
+				return s
+			}
+			s.add("缺少必备技能", r.Name, -r.Penalty)
+		}
+		for _, r := range p.Bonuses {
+			if !r.PerMatch {
+				if matched, ok := matchSkill(r.Children, r.AnyOf...); ok {
+					s.add("加分技能", r.Name+": "+matched, r.Bonus)
+				}
+				continue
+			}
+			for _, term := range r.AnyOf {
+				if matched, ok := matchSkill(r.Children, term); ok {
+					s.add("加分技能", r.Name+": "+matched, r.Bonus)
+				}
+			}
+		}
+
+		for _, required := range target.requiredSkills {
+			if !skill.MatchAny(skills, required) {
+				s.add("缺少岗位要求技能", required, -rules.Jobs.RequiredSkillPenalty)
+			}
+		}
+		for _, preferred := range target.preferredSkills {
+			if matched, ok := skill.Match(skills, preferred); ok {
+				s.add("岗位加分技能", preferred+": "+matched, rules.Jobs.PreferredSkillBonus)
+			}
+		}
+
+		if blog != "" {
+			s.add("博客", blog, rules.Technical.BlogBonus)
+		}
+		if github != "" {
+			s.add("Github", github, rules.Technical.GithubBonus)
+		}
+
+		s.limit(p.Cap)
+		s.Score = max(s.Score, 0)
+		return s
+	}
+	exp.Technical = calTechnicalScore(this.Skills, this.Blog, this.Github)
+
+	calIntentScore := func(expectCities []string, expectSalary int, intentPosition string) SubScore {
+		r := rules.Intent
+		s := SubScore{}
+		s.add("基础分", "", r.Base)
+		switch {
+		case len(expectCities) == 0:
+			s.add("期望城市", "未填写", r.NoCityBonus)
+		case utils.StringSliceContainsAny(target.cities, expectCities...):
+			s.add("期望城市", strings.Join(expectCities, "、"), r.CityMatchBonus)
+		default:
+			s.add("期望城市", strings.Join(expectCities, "、"), -r.CityMismatchPenalty)
+		}
+		if band := target.salaryBand; band != nil && expectSalary > 0 {
+			detail := fmt.Sprintf("%d（预算 %d-%d）", expectSalary, band.Min, band.Max)
+			switch {
+			case expectSalary <= band.Max:
+				s.add("期望薪资", detail, r.SalaryInBandBonus)
+			case float32(expectSalary) <= float32(band.Max)*(1+r.SalaryTolerance):
+				s.add("期望薪资", detail, -r.SalaryOverPenalty)
+			default:
+				s.add("期望薪资", detail, -r.SalaryFarOverPenalty)
+			}
+		}
+		if intentPosition != "" && target.title != "" {
+			if target.intentMatches(intentPosition) {
+				s.add("求职意向", intentPosition, r.PositionMatchBonus)
+			} else {
+				s.add("求职意向", intentPosition, -r.PositionMismatchPenalty)
+			}
+		}
+		s.limit(r.Cap)
+		s.Score = max(s.Score, 0)
+		return s
+	}
+	exp.Intent = calIntentScore(this.ExpectCities, this.ExpectSalary, this.IntentPosition)
+
+	// calcAvgScore calculates the weighted average of the sub-scores
+	calcAvgScore := func(w scoring.Weights) float32 {
+		total := exp.Experience.Score*w.Experience + exp.Education.Score*w.Education +
+			exp.Technical.Score*w.Technical + exp.Intent.Score*w.Intent
+		sum := w.Experience + w.Education + w.Technical + w.Intent
+		if sum == 0 {
+			return 0
+		}
+
+		// 计算平均值并保留一位小数
+		return format_score(total / sum)
+	}
+	exp.Weights = weights.of(target.position, rules)
+	exp.AverageScore = calcAvgScore(exp.Weights)
+	return exp
+}
+
+func (this *Talent) CalcScore() {
+	this.calcScore(currentWeights())
+}
+
+// calcScore scores the talent with the active rules and the given position weights
+func (this *Talent) calcScore(weights weightSet) {
+	rules := scoring.Active()
+	exp := this.explainScore(rules, weights, this.positionTarget(rules))
+	this.ExperienceScore = exp.Experience.Score
+	this.EducationScore = exp.Education.Score
+	this.TechnicalScore = exp.Technical.Score
+	this.IntentScore = exp.Intent.Score
+	this.AverageScore = exp.AverageScore
+	this.ScoreVersion = exp.ScoreVersion
+}
+
+// scoreVersion identifies the data a score was computed from, the rule version together with
+// the active university ranking, e.g. 2025.6+builtin
+func scoreVersion(rules *scoring.RuleSet) string {
+	return rules.Version + "+" + university.Version()
+}
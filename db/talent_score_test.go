@@ -0,0 +1,116 @@
+package db
+
+import (
+	"talents/scoring"
+	"talents/university"
+	"testing"
+)
+
+func TestExplainScore(t *testing.T) {
+	talents := []*Talent{
+		{Education: "本科", Universities: StringSlice{"清华大学"}, Major: "计算机科学与技术", Years: 5,
+			Companies: StringSlice{"阿里巴巴"}, Skills: StringSlice{"go", "mysql", "redis"}, JobPosition: "后端",
+			Blog: "https://blog.example", Github: "jia"},
+		{Education: "硕士", Skills: StringSlice{"java"}, JobPosition: "前端"},
+		{},
+	}
+	for _, talent := range talents {
+		exp := talent.ExplainScore()
+		for name, s := range map[string]SubScore{"experience": exp.Experience, "education": exp.Education,
+			"technical": exp.Technical, "intent": exp.Intent} {
+			var sum float32
+			for _, item := range s.Items {
+				sum += item.Score
+			}
+			if format_score(sum) != format_score(s.Raw) {
+				t.Errorf("%s: items add up to %v, want the raw score %v", name, sum, s.Raw)
+			}
+			if s.Capped && (s.Score != s.Cap || s.Raw <= s.Cap) {
+				t.Errorf("%s: score %v, raw %v, capped at %v", name, s.Score, s.Raw, s.Cap)
+			}
+		}
+
+		talent.CalcScore()
+		if talent.AverageScore != exp.AverageScore || talent.TechnicalScore != exp.Technical.Score ||
+			talent.ScoreVersion != exp.ScoreVersion {
+			t.Errorf("CalcScore = %v/%v/%q, want the explained %v/%v/%q", talent.AverageScore, talent.TechnicalScore,
+				talent.ScoreVersion, exp.AverageScore, exp.Technical.Score, exp.ScoreVersion)
+		}
+	}
+}
+
+func TestIntentScore(t *testing.T) {
+	r := scoring.Active().Intent
+	band := r.SalaryBands["后端"]
+	city := r.Cities[0]
+	tests := []struct {
+		name   string
+		talent Talent
+		want   float32
+	}{
+		{"no preferences", Talent{}, r.Base + r.NoCityBonus},
+		{"matching city, salary and position",
+			Talent{ExpectCities: StringSlice{city}, ExpectSalary: band.Max, JobPosition: "后端", IntentPosition: "后端开发"},
+			r.Base + r.CityMatchBonus + r.SalaryInBandBonus + r.PositionMatchBonus},
+		{"salary within the tolerance",
+			Talent{ExpectCities: StringSlice{"拉萨"}, ExpectSalary: band.Max + 1, JobPosition: "后端"},
+			r.Base - r.CityMismatchPenalty - r.SalaryOverPenalty},
+		{"salary far over the band",
+			Talent{ExpectSalary: band.Max * 2, JobPosition: "后端", IntentPosition: "前端"},
+			r.Base + r.NoCityBonus - r.SalaryFarOverPenalty - r.PositionMismatchPenalty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := format_score(max(min(tt.want, r.Cap), 0))
+			if got := tt.talent.ExplainScore().Intent.Score; got != want {
+				t.Errorf("intent score = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestScoreVersionFollowsRanking(t *testing.T) {
+	talent := &Talent{Education: "本科", Universities: StringSlice{"测试大学"}}
+	talent.CalcScore()
+	builtin := talent.ScoreVersion
+
+	data := []byte(`[{"univUp": "test", "univNameCn": "测试大学", "univNameEn": "Test University", "score": 900}]`)
+	if err := university.Use("2025-test", data); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(university.UseBuiltin)
+
+	exp := talent.ExplainScore()
+	if exp.ScoreVersion == builtin {
+		t.Fatalf("score version %q did not change with the ranking", builtin)
+	}
+	if exp.RuleVersion+"+2025-test" != exp.ScoreVersion {
+		t.Errorf("score version = %q, want the rule version with +2025-test", exp.ScoreVersion)
+	}
+}
+
+func TestActivateUniversityRanking(t *testing.T) {
+	openTestDB(t)
+	t.Cleanup(university.UseBuiltin)
+	data := []byte(`[{"univUp": "test", "univNameCn": "测试大学", "univNameEn": "Test University", "score": 900}]`)
+	for _, version := range []string{"2025-a", "2025-b"} {
+		if _, err := CreateUniversityRanking(version, data, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ActivateUniversityRanking("2025-a"); err != nil {
+		t.Fatal(err)
+	}
+	if university.Version() != "2025-a" {
+		t.Fatalf("version = %q, want 2025-a", university.Version())
+	}
+
+	// 写入失败时保留原来生效的版本
+	exec(t, "CREATE TRIGGER keep_ranking BEFORE UPDATE ON university_rankings BEGIN SELECT RAISE(ABORT, 'locked'); END")
+	if err := ActivateUniversityRanking("2025-b"); err == nil {
+		t.Fatal("activation succeeded, want the update to fail")
+	}
+	if university.Version() != "2025-a" {
+		t.Errorf("version = %q after a failed activation, want 2025-a", university.Version())
+	}
+}
@@ -0,0 +1,35 @@
+package db
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestCreateTalentIgnoresClientKeys(t *testing.T) {
+	openTestDB(t)
+	first := &Talent{Name: "甲", Employments: []*Employment{{Company: "阿里巴巴"}}}
+	if err := CreateTalent(first, Actor{}); err != nil {
+		t.Fatal(err)
+	}
+
+	second := &Talent{ID: first.ID, Name: "乙", DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true},
+		Employments: []*Employment{{ID: first.Employments[0].ID, Company: "腾讯"}}}
+	if err := CreateTalent(second, Actor{}); err != nil {
+		t.Fatal(err)
+	}
+	if second.ID == first.ID || second.DeletedAt.Valid {
+		t.Errorf("created talent %d, deleted %v, want a new talent outside the trash", second.ID, second.DeletedAt.Valid)
+	}
+	for id, want := range map[uint64]string{first.ID: "阿里巴巴", second.ID: "腾讯"} {
+		got, err := GetTalent(fmt.Sprint(id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Employments) != 1 || got.Employments[0].Company != want {
+			t.Errorf("talent %d employments = %v, want %s", id, got.Employments, want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ListTrashedTalents returns the talents in the trash, most recently deleted first
+func ListTrashedTalents() ([]*Talent, error) {
+	var talents []*Talent
+	err := withDetails().Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&talents).Error
+	if err != nil {
+		return nil, err
+	}
+	return talents, nil
+}
+
+// GetTrashedTalent returns a talent in the trash
+func GetTrashedTalent(id string) (*Talent, error) {
+	talentID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	var talent Talent
+	if err := withDetails().Unscoped().Where("deleted_at IS NOT NULL").First(&talent, talentID).Error; err != nil {
+		return nil, err
+	}
+	return &talent, nil
+}
+
+// GetTrashedTalentByPhone returns the talent in the trash holding a phone number
+func GetTrashedTalentByPhone(phone string) (*Talent, error) {
+	p := NormalizePhone(phone)
+	if p == "" {
+		return nil, errors.New("invalid phone number")
+	}
+	var talent Talent
+	if err := db.Unscoped().Where("phone = ? AND deleted_at IS NOT NULL", p).First(&talent).Error; err != nil {
+		return nil, err
+	}
+	return &talent, nil
+}
+
+// RestoreTalent takes a talent out of the trash
+func RestoreTalent(id string, actor Actor) error {
+	talentID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	return auditTalent(actor, talentID, func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&Talent{}).Where("id = ? AND deleted_at IS NOT NULL", talentID).Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// PurgeTalent permanently removes a talent in the trash with everything attached to it,
+// including its resume files. Audit log entries are kept.
+func PurgeTalent(id uint64, actor Actor) error {
+	var talent Talent
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL").First(&talent, id).Error; err != nil {
+		return err
+	}
+	var paths []string
+	if err := db.Model(&Resume{}).Where("talent_id = ?", id).Pluck("path", &paths).Error; err != nil {
+		return err
+	}
+	if talent.ResumePath != "" {
+		paths = append(paths, talent.ResumePath)
+	}
+
+	err := auditTalent(actor, id, func(tx *gorm.DB) error {
+		for _, model := range []any{&Employment{}, &EducationRecord{}, &Resume{}, &Interview{},
+			&StageTransition{}, &Comment{}, &TalentJob{}, &ScoreHistory{}} {
+			if err := tx.Delete(model, "talent_id = ?", id).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(&Talent{}, id).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			fmt.Printf("Error removing resume file %s: %v\n", path, err)
+		}
+	}
+	return nil
+}
+
+// PurgeExpiredTalents purges the talents that have been in the trash longer than retention
+// and returns how many were purged. A talent that fails to purge does not stop the others,
+// the failures are returned together.
+func PurgeExpiredTalents(retention time.Duration) (int, error) {
+	var ids []uint64
+	err := db.Unscoped().Model(&Talent{}).Where("deleted_at < ?", time.Now().Add(-retention)).Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	var errs []error
+	for _, id := range ids {
+		if err := PurgeTalent(id, Actor{}); err != nil {
+			errs = append(errs, fmt.Errorf("purge talent %d: %w", id, err))
+			continue
+		}
+		purged++
+	}
+	return purged, errors.Join(errs...)
+}
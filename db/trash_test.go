@@ -0,0 +1,86 @@
+package db
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestTrashedTalentIDs(t *testing.T) {
+	openTestDB(t)
+	for _, name := range []string{"甲", "乙"} {
+		if err := CreateTalent(&Talent{Name: name}, Actor{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := DeleteTalent("1", Actor{}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"1", true},
+		{"2", false}, // 不在回收站中
+		{"1 OR 1=1", false},
+		{"abc", false},
+		{"0", false},
+	}
+	for _, tt := range tests {
+		talent, err := GetTrashedTalent(tt.id)
+		if tt.want {
+			if err != nil || talent.ID != 1 {
+				t.Errorf("GetTrashedTalent(%q) = %v, %v, want talent 1", tt.id, talent, err)
+			}
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("GetTrashedTalent(%q) = %v, want not found", tt.id, err)
+		}
+		if err := RestoreTalent(tt.id, Actor{}); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("RestoreTalent(%q) = %v, want not found", tt.id, err)
+		}
+	}
+}
+
+func TestPurgeExpiredTalents(t *testing.T) {
+	openTestDB(t)
+	for _, name := range []string{"甲", "乙", "丙", "丁"} {
+		talent := &Talent{Name: name}
+		if err := CreateTalent(talent, Actor{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := CreateComment(&Comment{TalentID: talent.ID, AuthorID: 1, Body: "备注"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []string{"1", "2", "3"} {
+		if err := DeleteTalent(id, Actor{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 乙、丙已过保留期，其中乙的评论无法删除；甲刚移入回收站，丁未删除
+	expired := time.Now().Add(-48 * time.Hour)
+	if err := db.Unscoped().Model(&Talent{}).Where("id IN ?", []uint64{2, 3}).Update("deleted_at", expired).Error; err != nil {
+		t.Fatal(err)
+	}
+	exec(t, "CREATE TRIGGER keep_comment BEFORE DELETE ON comments WHEN OLD.talent_id = 2 BEGIN SELECT RAISE(ABORT, 'locked'); END")
+
+	n, err := PurgeExpiredTalents(24 * time.Hour)
+	if n != 1 {
+		t.Errorf("purged = %d, want 1", n)
+	}
+	if err == nil || !strings.Contains(err.Error(), "purge talent 2") {
+		t.Errorf("err = %v, want the failure of talent 2", err)
+	}
+	var remaining []uint64
+	if err := db.Unscoped().Model(&Talent{}).Order("id").Pluck("id", &remaining).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 3 || remaining[0] != 1 || remaining[1] != 2 || remaining[2] != 4 {
+		t.Errorf("remaining talents = %v, want [1 2 4]", remaining)
+	}
+}
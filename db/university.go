@@ -0,0 +1,149 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"talents/university"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UniversityRanking 上传的院校排名数据版本，同一时间至多一个版本生效，都未生效时使用内置数据
+type UniversityRanking struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Version   string    `gorm:"size:64;uniqueIndex" json:"version"`
+	Data      string    `gorm:"size:16777216" json:"-"` // 超过 mysql text 的 64KB 上限，按长度映射为 16MB 的 mediumtext
+	Count     int       `json:"count"`
+	Active    bool      `json:"active"`
+	CreatedBy uint      `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// loadUniversityRanking switches the university registry to the active uploaded version, if any
+func loadUniversityRanking() error {
+	var rankings []*UniversityRanking
+	if err := db.Where("active = ?", true).Limit(1).Find(&rankings).Error; err != nil {
+		return err
+	}
+	if len(rankings) == 0 {
+		return nil
+	}
+	return university.Use(rankings[0].Version, []byte(rankings[0].Data))
+}
+
+// ListUniversityRankings lists the uploaded ranking versions without their data, newest first
+func ListUniversityRankings() ([]*UniversityRanking, error) {
+	var rankings []*UniversityRanking
+	if err := db.Omit("data").Order("created_at DESC, id DESC").Find(&rankings).Error; err != nil {
+		return nil, err
+	}
+	return rankings, nil
+}
+
+// CreateUniversityRanking validates and stores a new ranking version without activating it
+func CreateUniversityRanking(version string, data []byte, uid uint) (*UniversityRanking, error) {
+	if version == "" || version == university.BuiltinVersion {
+		return nil, fmt.Errorf("invalid version %q", version)
+	}
+	universities, err := university.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	ranking := &UniversityRanking{
+		Version:   version,
+		Data:      string(data),
+		Count:     len(universities),
+		CreatedBy: uid,
+	}
+	if err := db.Create(ranking).Error; err != nil {
+		return nil, err
+	}
+	return ranking, nil
+}
+
+// ActivateUniversityRanking makes a stored version, or the builtin data, the active university
+// ranking. The registry switches only after the change is committed.
+func ActivateUniversityRanking(version string) error {
+	if version == university.BuiltinVersion {
+		if err := db.Model(&UniversityRanking{}).Where("active = ?", true).Update("active", false).Error; err != nil {
+			return err
+		}
+		university.UseBuiltin()
+		return nil
+	}
+
+	var ranking UniversityRanking
+	if err := db.Where("version = ?", version).First(&ranking).Error; err != nil {
+		return err
+	}
+	loaded, err := university.Load(ranking.Version, []byte(ranking.Data))
+	if err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&UniversityRanking{}).Where("active = ?", true).Update("active", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&ranking).Update("active", true).Error
+	})
+	if err != nil {
+		return err
+	}
+	loaded.Activate()
+	return nil
+}
+
+// UnresolvedUniversity 院校库中无法解析的院校名称及引用它的人才数
+type UnresolvedUniversity struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// UnresolvedUniversities lists the stored university names, from both talents and their
+// education history, that the university registry cannot resolve
+func UnresolvedUniversities() ([]UnresolvedUniversity, error) {
+	var talents []*Talent
+	if err := db.Select("id", "universities").Find(&talents).Error; err != nil {
+		return nil, err
+	}
+	var educations []*EducationRecord
+	if err := db.Select("talent_id", "school").Find(&educations).Error; err != nil {
+		return nil, err
+	}
+
+	// 同一人才多次出现的名称只计一次
+	talentsByName := make(map[string]map[uint64]bool)
+	add := func(name string, talentID uint64) {
+		if name == "" {
+			return
+		}
+		if _, ok := university.Resolve(name); ok {
+			return
+		}
+		if talentsByName[name] == nil {
+			talentsByName[name] = make(map[uint64]bool)
+		}
+		talentsByName[name][talentID] = true
+	}
+	for _, t := range talents {
+		for _, name := range t.Universities {
+			add(name, t.ID)
+		}
+	}
+	for _, e := range educations {
+		add(e.School, e.TalentID)
+	}
+
+	unresolved := make([]UnresolvedUniversity, 0, len(talentsByName))
+	for name, ids := range talentsByName {
+		unresolved = append(unresolved, UnresolvedUniversity{Name: name, Count: len(ids)})
+	}
+	sort.Slice(unresolved, func(i, j int) bool {
+		if unresolved[i].Count != unresolved[j].Count {
+			return unresolved[i].Count > unresolved[j].Count
+		}
+		return unresolved[i].Name < unresolved[j].Name
+	})
+	return unresolved, nil
+}
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"maps"
+	"sync"
+	"talents/scoring"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WeightProfile 岗位平均分权重，覆盖评分规则中的默认权重
+type WeightProfile struct {
+	JobPosition string    `gorm:"primaryKey" json:"jobPosition"`
+	Experience  float32   `json:"experience"`
+	Education   float32   `json:"education"`
+	Technical   float32   `json:"technical"`
+	Intent      float32   `json:"intent"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	UpdatedBy   uint      `json:"updatedBy"`
+}
+
+func (w *WeightProfile) Weights() scoring.Weights {
+	return scoring.Weights{
+		Experience: w.Experience,
+		Education:  w.Education,
+		Technical:  w.Technical,
+		Intent:     w.Intent,
+	}
+}
+
+// weightSet 岗位到权重的映射，未配置的岗位使用规则中的默认权重
+type weightSet map[string]scoring.Weights
+
+// of returns the weights for a position, falling back to the rule set defaults
+func (s weightSet) of(position string, rules *scoring.RuleSet) scoring.Weights {
+	if w, ok := s[position]; ok {
+		return w
+	}
+	return rules.Weights
+}
+
+// weightCache caches weight profiles so that scoring doesn't hit the database per talent.
+// The map is replaced as a whole and never modified in place.
+var weightCache struct {
+	sync.RWMutex
+	m weightSet
+}
+
+// weightChanges serializes weight profile changes, so the cache follows the commit order
+var weightChanges sync.Mutex
+
+func currentWeights() weightSet {
+	weightCache.RLock()
+	defer weightCache.RUnlock()
+	return weightCache.m
+}
+
+func loadWeightProfiles() error {
+	profiles, err := ListWeightProfiles()
+	if err != nil {
+		return err
+	}
+	m := make(weightSet, len(profiles))
+	for _, p := range profiles {
+		m[p.JobPosition] = p.Weights()
+	}
+	weightCache.Lock()
+	weightCache.m = m
+	weightCache.Unlock()
+	return nil
+}
+
+// PositionWeights returns the weights for a position, falling back to the rule set defaults
+func PositionWeights(position string, rules *scoring.RuleSet) scoring.Weights {
+	return currentWeights().of(position, rules)
+}
+
+func ListWeightProfiles() ([]*WeightProfile, error) {
+	var profiles []*WeightProfile
+	if err := db.Find(&profiles).Error; err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// SaveWeightProfile saves the weight profile of a position and recalculates all scores with it
+// in the same transaction
+func SaveWeightProfile(p *WeightProfile, actor Actor) (*RecalculationResult, error) {
+	if err := p.Weights().Validate(); err != nil {
+		return nil, err
+	}
+	return changeWeights(actor, func(weights weightSet) { weights[p.JobPosition] = p.Weights() },
+		func(tx *gorm.DB) error { return tx.Save(p).Error })
+}
+
+// DeleteWeightProfile removes the weight profile of a position and recalculates all scores
+// with the default weights in the same transaction
+func DeleteWeightProfile(position string, actor Actor) (*RecalculationResult, error) {
+	return changeWeights(actor, func(weights weightSet) { delete(weights, position) },
+		func(tx *gorm.DB) error { return tx.Delete(&WeightProfile{}, "job_position = ?", position).Error })
+}
+
+// changeWeights previews the scores under the changed weights, then writes the profile and the
+// new scores in one transaction. The cache switches to the new weights once it commits.
+func changeWeights(actor Actor, change func(weights weightSet), write func(tx *gorm.DB) error) (*RecalculationResult, error) {
+	weightChanges.Lock()
+	defer weightChanges.Unlock()
+
+	weights := maps.Clone(currentWeights())
+	if weights == nil {
+		weights = make(weightSet)
+	}
+	change(weights)
+	result, err := previewRecalculation(weights)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := write(tx); err != nil {
+			return err
+		}
+		return applyRecalculation(tx, result, TriggerWeights, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Applied = true
+
+	weightCache.Lock()
+	weightCache.m = weights
+	weightCache.Unlock()
+	return result, nil
+}
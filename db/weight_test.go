@@ -0,0 +1,82 @@
+package db
+
+import (
+	"talents/scoring"
+	"testing"
+)
+
+func TestSaveWeightProfile(t *testing.T) {
+	tests := []struct {
+		name      string
+		failScore bool
+	}{
+		{name: "saved with the new scores"},
+		{name: "failed recalculation rolls back the profile", failScore: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openTestDB(t)
+			if err := loadWeightProfiles(); err != nil {
+				t.Fatal(err)
+			}
+			talent := createScoredTalent(t, "甲")
+			if tt.failScore {
+				exec(t, "CREATE TRIGGER fail_score BEFORE INSERT ON score_histories BEGIN SELECT RAISE(ABORT, 'score unavailable'); END")
+			}
+			defaults := PositionWeights("后端", scoring.Active())
+			weights := scoring.Weights{Experience: 0, Education: 0, Technical: 1, Intent: 0}
+
+			_, err := SaveWeightProfile(&WeightProfile{JobPosition: "后端", Experience: weights.Experience,
+				Education: weights.Education, Technical: weights.Technical, Intent: weights.Intent}, Actor{UserID: 1})
+			if (err != nil) != tt.failScore {
+				t.Fatalf("save = %v, want error %v", err, tt.failScore)
+			}
+
+			profiles, err := ListWeightProfiles()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var stored Talent
+			if err := db.First(&stored, talent.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			history, err := ListScoreHistory(talent.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.failScore {
+				if len(profiles) != 0 || PositionWeights("后端", scoring.Active()) != defaults {
+					t.Errorf("profiles = %d, weights = %v, want the default weights", len(profiles), PositionWeights("后端", scoring.Active()))
+				}
+				if stored.AverageScore != talent.AverageScore {
+					t.Errorf("average score = %v, want %v", stored.AverageScore, talent.AverageScore)
+				}
+				if len(history) != 0 {
+					t.Errorf("history = %d snapshots, want none", len(history))
+				}
+				return
+			}
+			if len(profiles) != 1 || PositionWeights("后端", scoring.Active()) != weights {
+				t.Errorf("profiles = %d, weights = %v, want %v", len(profiles), PositionWeights("后端", scoring.Active()), weights)
+			}
+			if stored.AverageScore != stored.TechnicalScore {
+				t.Errorf("average score = %v, want the technical score %v", stored.AverageScore, stored.TechnicalScore)
+			}
+			if len(history) != 1 || history[0].Trigger != TriggerWeights || history[0].UserID != 1 ||
+				history[0].AverageScore != stored.AverageScore {
+				t.Errorf("history = %+v, want one %q snapshot of %v by user 1", history, TriggerWeights, stored.AverageScore)
+			}
+
+			if _, err := DeleteWeightProfile("后端", Actor{UserID: 1}); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.First(&stored, talent.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			if PositionWeights("后端", scoring.Active()) != defaults || stored.AverageScore != talent.AverageScore {
+				t.Errorf("after delete: weights = %v, average score = %v, want the defaults and %v",
+					PositionWeights("后端", scoring.Active()), stored.AverageScore, talent.AverageScore)
+			}
+		})
+	}
+}
@@ -2,15 +2,49 @@ package main
 
 import (
 	"log"
+	"os"
 	"talents/api"
-
-	"github.com/joho/godotenv"
+	"talents/config"
+	"talents/db"
+	"talents/scoring"
+	"time"
 )
 
+// purgeTrash periodically removes talents that have outlived the trash retention period
+func purgeTrash() {
+	retention := time.Duration(config.TRASH_RETENTION_DAYS) * 24 * time.Hour
+	for ; ; time.Sleep(time.Hour) {
+		n, err := db.PurgeExpiredTalents(retention)
+		if err != nil {
+			log.Printf("Failed to purge trash: %v", err)
+		}
+		if n > 0 {
+			log.Printf("Purged %d talents from the trash", n)
+		}
+	}
+}
+
 func main() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
+	if err := config.Load(); err != nil {
+		log.Fatal(err)
+	}
+	if _, err := scoring.Reload(); err != nil {
+		log.Fatalf("Failed to load scoring rules: %v", err)
+	}
+	if err := db.Open(config.DB_DRIVER, config.DB_DSN); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := db.Start(); err != nil {
+		log.Fatalf("Failed to start database: %v", err)
+	}
+	if config.TRASH_RETENTION_DAYS > 0 {
+		go purgeTrash()
 	}
 	r := api.Router()
 	r.Run() // listen and serve on 0.0.0.0:8080
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"talents/db"
+)
+
+const migrateUsage = `usage: talents migrate [command] [--drop-data]
+
+commands:
+  up        apply all pending migrations (default)
+  down      roll back the latest migration
+  to N      migrate up or down to version N
+  status    list migrations and whether they are applied
+
+A rollback that would drop tables holding data is refused unless --drop-data is given.`
+
+// migrate runs the migrate subcommand
+func migrate(args []string) error {
+	dropData := slices.Contains(args, "--drop-data")
+	args = slices.DeleteFunc(slices.Clone(args), func(arg string) bool { return arg == "--drop-data" })
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+	switch command {
+	case "up":
+		return db.Migrate(db.LatestVersion(), dropData)
+	case "down":
+		version, err := db.SchemaVersion()
+		if err != nil {
+			return err
+		}
+		if version == 0 {
+			return errors.New("no migration to roll back")
+		}
+		target := 0
+		for _, m := range db.Migrations() {
+			if m.Version < version {
+				target = m.Version
+			}
+		}
+		return db.Migrate(target, dropData)
+	case "to":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		target, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %s", args[1])
+		}
+		return db.Migrate(target, dropData)
+	case "status":
+		return printMigrationStatus()
+	}
+	return errors.New(migrateUsage)
+}
+
+func printMigrationStatus() error {
+	version, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	applied, err := db.AppliedMigrations()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("schema version %d, latest %d\n", version, db.LatestVersion())
+	for _, m := range db.Migrations() {
+		state := "pending"
+		if t, ok := applied[m.Version]; ok {
+			state = "applied " + t.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%4d  %-45s %s\n", m.Version, m.Name, state)
+	}
+	if version > db.LatestVersion() {
+		fmt.Printf("database schema version %d is newer than this build\n", version)
+	}
+	return nil
+}
@@ -3,14 +3,18 @@ package pdf
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
 	"talents/config"
 	"talents/db"
 	"talents/llm"
+	"talents/scoring"
+	"talents/skill"
 	"time"
 
 	"github.com/ledongthuc/pdf"
@@ -110,60 +114,118 @@ const PROMPT = `<optimized_prompt>
 
 <instructions>
 1. 分析用户输入，提取关键信息
-2. 获取应聘者姓名、年龄、手机号、邮箱、学历、上过的大学列表、专业、技能、工作年限、籍贯、意向城市列表、期望薪资、工作过的公司列表、博客地址、Github地址、应聘岗位
+2. 获取应聘者姓名、年龄、手机号、邮箱、学历、上过的大学列表、专业、技能、工作年限、籍贯、意向城市列表、期望薪资、工作过的公司列表、博客地址、Github地址、应聘岗位、求职意向
 3. 仅返回指定内容，不要返回多余内容
 4. 学历只返回最高的，选项：本科、硕士、博士
 5. 技能包括但不限于：java、python、c、大模型应用、大模型微调，英文全部用小写
-6. 返回的手机号为数字，不要返回字符串
+6. 手机号以字符串返回，只保留数字和开头的 +，简历中没有手机号时返回空字符串
 7. 输出时不要返回 markdown 标识
-8. 应聘岗位指简历中明确提到的求职意向岗位，如果没有明确提到，请根据简历内容推断最可能的岗位，应聘岗位只能是：前端、后端、运维、嵌入式、算法
-9. 手机号为 11 位
+8. 应聘岗位指简历中明确提到的求职意向岗位，如果没有明确提到，请根据简历内容推断最可能的岗位，应聘岗位只能是：%s
+9. 国内手机号为 11 位，境外号码需带国家或地区区号，如 +85291234567
 10. 输出的 json 中不要带注释
+11. 求职意向指简历中原文写明的求职意向，没有写明时返回空字符串，不要推断
+12. 工作经历按简历原文逐段返回，开始、结束时间格式为 YYYY-MM，仍在职的结束时间返回“至今”，实习经历也需返回
+13. 教育经历按简历原文逐段返回，学位选项：大专、本科、硕士、博士，专升本需分别返回大专和本科两段，非全日制（在职、自考、成人教育、网络教育等）fullTime 返回 false
 </instructions>
 
 <output_format>
-{"name":"xx","age":1,"phone":13323313233,"email":"11@qq.com","education":"xx","universities":["xx","xx"],"major":"xx","skills":["x1","x2"],"years":1,"native":"xx","expectCities":["xx","xx"],"expectSalary":10000,"companies":["xx","xx"],"blog":"xx","github":"xx","jobPosition":"xx"}
+{"name":"xx","age":1,"phone":"13323313233","email":"11@qq.com","education":"xx","universities":["xx","xx"],"major":"xx","skills":["x1","x2"],"years":1,"native":"xx","expectCities":["xx","xx"],"expectSalary":10000,"companies":["xx","xx"],"blog":"xx","github":"xx","jobPosition":"xx","intentPosition":"xx","employments":[{"company":"xx","title":"xx","start":"2020-01","end":"2023-06","description":"xx"}],"educations":[{"school":"xx","degree":"本科","major":"xx","start":"2016-09","end":"2020-06","fullTime":true}]}
 </output_format>
 </optimized_prompt>`
 
+// jobPositions lists the positions the LLM may choose from: those with scoring rules
+// plus the categories of open jobs
+func jobPositions() (string, error) {
+	seen := make(map[string]bool)
+	for name := range scoring.Active().Technical.Positions {
+		seen[name] = true
+	}
+	jobs, err := db.ListJobs(db.JobOpen)
+	if err != nil {
+		return "", err
+	}
+	for _, job := range jobs {
+		if job.Category != "" {
+			seen[job.Category] = true
+		}
+	}
+	positions := make([]string, 0, len(seen))
+	for name := range seen {
+		positions = append(positions, name)
+	}
+	sort.Strings(positions)
+	return strings.Join(positions, "、"), nil
+}
+
+// extractJSON returns the JSON object in a model reply, dropping any prose or code fence
+// around it. The object runs to the last closing brace since it nests employments and
+// educations.
+func extractJSON(resp string) (string, bool) {
+	start := strings.Index(resp, "{")
+	end := strings.LastIndex(resp, "}")
+	if start == -1 || end < start {
+		return "", false
+	}
+	return resp[start : end+1], true
+}
+
+// parseReply decodes the talent in a model reply
+func parseReply(resp string) (*db.Talent, error) {
+	data, ok := extractJSON(resp)
+	if !ok {
+		return nil, errors.New("no JSON object in the reply")
+	}
+	talent := &db.Talent{}
+	if err := json.Unmarshal([]byte(data), talent); err != nil {
+		return nil, err
+	}
+	return talent, nil
+}
+
 // GenerateTalentFromPDF parses a PDF resume and extracts relevant information to create a Talent
 func GenerateTalentFromPDF(path string) (*db.Talent, error) {
+	talent, _, err := ParseResume(path)
+	return talent, err
+}
+
+// ParseResume parses a PDF resume into a Talent, also returning the extracted text
+func ParseResume(path string) (*db.Talent, string, error) {
 
 	// Extract text from PDF
 	text, err := ExtractText(path)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	fmt.Println(text)
 
+	positions, err := jobPositions()
+	if err != nil {
+		return nil, "", err
+	}
+
 	// Parse the extracted text to create a Talent
-	talent := &db.Talent{}
+	var talent *db.Talent
 	for _ = range 3 {
-		query := fmt.Sprintf(PROMPT, time.Now().Format("2006-01-02"), text)
+		query := fmt.Sprintf(PROMPT, time.Now().Format("2006-01-02"), text, positions)
 		resp, err := llm.Chat(query)
 		if err != nil {
 			continue
 		}
 		fmt.Println(resp)
 
-		start := strings.Index(resp, "{")
-		if start == -1 {
-			continue
-		}
-		end := strings.Index(resp, "}")
-		if end == -1 {
-			continue
-		}
-		fmt.Println(start, end)
-		resp = resp[start : end+1]
-
-		err = json.Unmarshal([]byte(resp), &talent)
-		if err == nil {
+		if talent, err = parseReply(resp); err == nil {
 			break
 		}
 	}
+	if talent == nil {
+		return nil, "", errors.New("failed to parse the resume after 3 attempts")
+	}
 
+	talent.NormalizeEmployments()
+	talent.NormalizeEducations()
+	talent.Skills = skill.Normalize(talent.Skills)
+	talent.Companies = db.NormalizeCompanies(talent.Companies)
 	talent.CalcScore()
 
-	return talent, nil
+	return talent, text, nil
 }
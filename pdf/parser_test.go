@@ -0,0 +1,49 @@
+package pdf
+
+import (
+	"testing"
+)
+
+const nestedReply = `{"name":"张三","phone":"13323313233","education":"本科","skills":["go","mysql"],"years":5,` +
+	`"employments":[{"company":"字节跳动","title":"后端","start":"2020-01","end":"2023-06","description":"推荐系统"},` +
+	`{"company":"腾讯","title":"后端","start":"2023-07","end":"至今","description":"{json} 接口"}],` +
+	`"educations":[{"school":"浙江大学","degree":"本科","major":"计算机","start":"2016-09","end":"2020-06","fullTime":true}]}`
+
+func TestParseReply(t *testing.T) {
+	tests := []struct {
+		name        string
+		reply       string
+		wantErr     bool
+		employments int
+		educations  int
+	}{
+		{name: "nested reply", reply: nestedReply, employments: 2, educations: 1},
+		{name: "code fence", reply: "```json\n" + nestedReply + "\n```", employments: 2, educations: 1},
+		{name: "prose around", reply: "解析结果如下：\n" + nestedReply + "\n以上。", employments: 2, educations: 1},
+		{name: "flat reply", reply: `{"name":"李四","skills":["java"]}`},
+		{name: "no JSON", reply: "无法解析该简历", wantErr: true},
+		{name: "truncated", reply: `{"name":"王五","employments":[{"company":"x"}`, wantErr: true},
+		{name: "closing brace first", reply: `} {"name":"x"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			talent, err := parseReply(tt.reply)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseReply succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseReply: %v", err)
+			}
+			if talent.Name == "" {
+				t.Error("name is empty")
+			}
+			if len(talent.Employments) != tt.employments || len(talent.Educations) != tt.educations {
+				t.Errorf("got %d employments and %d educations, want %d and %d",
+					len(talent.Employments), len(talent.Educations), tt.employments, tt.educations)
+			}
+		})
+	}
+}
@@ -0,0 +1,404 @@
+package scoring
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"talents/config"
+	"talents/university"
+)
+
+// CompanyTier 公司档位。公司库中标记为该档位的公司取该档位分数，
+// Companies 用于匹配公司库中未收录的公司名称
+type CompanyTier struct {
+	Name      string   `json:"name"`
+	Score     float32  `json:"score"`
+	Companies []string `json:"companies"`
+}
+
+// YearsBonus 工作年限达到 MinYears 时加分
+type YearsBonus struct {
+	MinYears int     `json:"minYears"`
+	Bonus    float32 `json:"bonus"`
+}
+
+// ExperienceRules 经验分规则。有结构化工作经历时，公司档位加分按在该公司的任职时长折算，
+// 满 TenureFullMonths 个月计满分；为 0 时不折算
+type ExperienceRules struct {
+	Base             float32       `json:"base"`
+	CompanyTiers     []CompanyTier `json:"companyTiers"`
+	YearsBonuses     []YearsBonus  `json:"yearsBonuses"`
+	TenureFullMonths int           `json:"tenureFullMonths"`
+}
+
+// SchoolWeights 有结构化教育经历时，本科与研究生院校分的权重。非全日制院校分乘以 PartTimeFactor，
+// 专升本扣 UpgradePenalty 分
+type SchoolWeights struct {
+	Undergraduate  float32 `json:"undergraduate"`
+	Graduate       float32 `json:"graduate"`
+	PartTimeFactor float32 `json:"partTimeFactor"`
+	UpgradePenalty float32 `json:"upgradePenalty"`
+}
+
+// SchoolBonus 任一院校满足条件时加分。Tier 为 985、211 或 双一流，CSRating 为计算机学科评估的最低等级，
+// 同时配置时需都满足
+type SchoolBonus struct {
+	Name     string  `json:"name"`
+	Tier     string  `json:"tier"`
+	CSRating string  `json:"csRating"`
+	Bonus    float32 `json:"bonus"`
+}
+
+type EducationRules struct {
+	MajorKeywords []string           `json:"majorKeywords"`
+	MajorBonus    float32            `json:"majorBonus"`
+	DegreeBonuses map[string]float32 `json:"degreeBonuses"`
+	Schools       SchoolWeights      `json:"schools"`
+	SchoolBonuses []SchoolBonus      `json:"schoolBonuses"`
+	Cap           float32            `json:"cap"`
+}
+
+// RequiredSkill 必备技能，缺失时扣 Penalty 分；Reject 为 true 时直接记为 RejectScore。
+// Children 为 true 时下级技能也算具备，如 数据库 可由 mysql 满足
+type RequiredSkill struct {
+	Name     string   `json:"name"`
+	AnyOf    []string `json:"anyOf"`
+	Penalty  float32  `json:"penalty"`
+	Reject   bool     `json:"reject"`
+	Children bool     `json:"children"`
+}
+
+// BonusSkill 加分技能，PerMatch 为 true 时每命中一项都加分，Children 为 true 时下级技能也算命中
+type BonusSkill struct {
+	Name     string   `json:"name"`
+	AnyOf    []string `json:"anyOf"`
+	Bonus    float32  `json:"bonus"`
+	PerMatch bool     `json:"perMatch"`
+	Children bool     `json:"children"`
+}
+
+// PositionRules 单个岗位的技术分规则，Base、Cap 为 0 时沿用 TechnicalRules 的配置
+type PositionRules struct {
+	Base     float32         `json:"base"`
+	Cap      float32         `json:"cap"`
+	Required []RequiredSkill `json:"required"`
+	Bonuses  []BonusSkill    `json:"bonuses"`
+}
+
+type TechnicalRules struct {
+	Base        float32                  `json:"base"`
+	BlogBonus   float32                  `json:"blogBonus"`
+	GithubBonus float32                  `json:"githubBonus"`
+	RejectScore float32                  `json:"rejectScore"`
+	Cap         float32                  `json:"cap"`
+	Positions   map[string]PositionRules `json:"positions"`
+}
+
+// SalaryBand 岗位薪资预算（月薪，元）
+type SalaryBand struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// IntentRules 意向分规则：期望城市、期望薪资与岗位意向
+type IntentRules struct {
+	Base                    float32               `json:"base"`
+	Cities                  []string              `json:"cities"` // 办公城市
+	CityMatchBonus          float32               `json:"cityMatchBonus"`
+	NoCityBonus             float32               `json:"noCityBonus"` // 未填写期望城市
+	CityMismatchPenalty     float32               `json:"cityMismatchPenalty"`
+	SalaryBands             map[string]SalaryBand `json:"salaryBands"`
+	SalaryInBandBonus       float32               `json:"salaryInBandBonus"`
+	SalaryTolerance         float32               `json:"salaryTolerance"` // 超出预算上限的容忍比例
+	SalaryOverPenalty       float32               `json:"salaryOverPenalty"`
+	SalaryFarOverPenalty    float32               `json:"salaryFarOverPenalty"`
+	PositionMatchBonus      float32               `json:"positionMatchBonus"`
+	PositionMismatchPenalty float32               `json:"positionMismatchPenalty"`
+	Cap                     float32               `json:"cap"`
+}
+
+// MatchWeights 人岗匹配度中各项要求的权重
+type MatchWeights struct {
+	RequiredSkills  float32 `json:"requiredSkills"`
+	PreferredSkills float32 `json:"preferredSkills"`
+	Years           float32 `json:"years"`
+	Education       float32 `json:"education"`
+	City            float32 `json:"city"`
+	Salary          float32 `json:"salary"`
+}
+
+func (w MatchWeights) Sum() float32 {
+	return w.RequiredSkills + w.PreferredSkills + w.Years + w.Education + w.City + w.Salary
+}
+
+// JobRules 按招聘需求评分时，岗位要求带来的加减分，以及人岗匹配度的权重
+type JobRules struct {
+	RequiredSkillPenalty  float32      `json:"requiredSkillPenalty"`
+	PreferredSkillBonus   float32      `json:"preferredSkillBonus"`
+	EducationFloorPenalty float32      `json:"educationFloorPenalty"`
+	Match                 MatchWeights `json:"match"`
+}
+
+// Weights 平均分中各分项的权重
+type Weights struct {
+	Experience float32 `json:"experience"`
+	Education  float32 `json:"education"`
+	Technical  float32 `json:"technical"`
+	Intent     float32 `json:"intent"`
+}
+
+func (w Weights) Validate() error {
+	if w.Experience < 0 || w.Education < 0 || w.Technical < 0 || w.Intent < 0 {
+		return errors.New("weights must not be negative")
+	}
+	if w.Experience+w.Education+w.Technical+w.Intent == 0 {
+		return errors.New("weights must not all be zero")
+	}
+	return nil
+}
+
+// RuleSet 一套完整的评分规则
+type RuleSet struct {
+	Version    string          `json:"version"`
+	Experience ExperienceRules `json:"experience"`
+	Education  EducationRules  `json:"education"`
+	Technical  TechnicalRules  `json:"technical"`
+	Intent     IntentRules     `json:"intent"`
+	Jobs       JobRules        `json:"jobs"`
+	Weights    Weights         `json:"weights"`
+}
+
+// Tier 按名称查找公司档位
+func (rs *RuleSet) Tier(name string) (CompanyTier, bool) {
+	for _, tier := range rs.Experience.CompanyTiers {
+		if tier.Name == name {
+			return tier, true
+		}
+	}
+	return CompanyTier{}, false
+}
+
+// Position 返回岗位规则，Base、Cap 未配置时补全为默认值
+func (rs *RuleSet) Position(name string) PositionRules {
+	p := rs.Technical.Positions[name]
+	if p.Base == 0 {
+		p.Base = rs.Technical.Base
+	}
+	if p.Cap == 0 {
+		p.Cap = rs.Technical.Cap
+	}
+	return p
+}
+
+func validScore(score float32) bool {
+	return score >= 0 && score <= 10
+}
+
+// Validate 校验规则集
+func (rs *RuleSet) Validate() error {
+	if rs.Version == "" {
+		return errors.New("version is required")
+	}
+	if !validScore(rs.Experience.Base) {
+		return fmt.Errorf("experience.base %v out of range [0, 10]", rs.Experience.Base)
+	}
+	for _, tier := range rs.Experience.CompanyTiers {
+		if !validScore(tier.Score) {
+			return fmt.Errorf("experience tier %q: score %v out of range [0, 10]", tier.Name, tier.Score)
+		}
+		if tier.Name == "" {
+			return errors.New("experience tier name is required")
+		}
+	}
+	if rs.Experience.TenureFullMonths < 0 {
+		return errors.New("experience.tenureFullMonths must not be negative")
+	}
+	for _, yb := range rs.Experience.YearsBonuses {
+		if yb.MinYears <= 0 {
+			return fmt.Errorf("experience yearsBonuses: minYears must be positive, got %d", yb.MinYears)
+		}
+	}
+	if rs.Education.Cap <= 0 || rs.Education.Cap > 10 {
+		return fmt.Errorf("education.cap %v out of range (0, 10]", rs.Education.Cap)
+	}
+	if sw := rs.Education.Schools; sw.Undergraduate < 0 || sw.Graduate < 0 || sw.Undergraduate+sw.Graduate == 0 {
+		return errors.New("education.schools weights must not be negative or all zero")
+	}
+	if sw := rs.Education.Schools; sw.PartTimeFactor < 0 || sw.PartTimeFactor > 1 || sw.UpgradePenalty < 0 {
+		return errors.New("education.schools partTimeFactor must be within [0, 1] and upgradePenalty must not be negative")
+	}
+	for _, b := range rs.Education.SchoolBonuses {
+		if b.Tier == "" && b.CSRating == "" {
+			return fmt.Errorf("school bonus %q needs a tier or csRating", b.Name)
+		}
+		if b.Tier != "" && !university.ValidTier(b.Tier) {
+			return fmt.Errorf("school bonus %q: unknown tier %q", b.Name, b.Tier)
+		}
+		if b.CSRating != "" && !university.ValidCSRating(b.CSRating) {
+			return fmt.Errorf("school bonus %q: unknown csRating %q", b.Name, b.CSRating)
+		}
+	}
+	t := rs.Technical
+	if !validScore(t.Base) || !validScore(t.RejectScore) {
+		return errors.New("technical.base and technical.rejectScore must be within [0, 10]")
+	}
+	if t.Cap <= 0 || t.Cap > 10 {
+		return fmt.Errorf("technical.cap %v out of range (0, 10]", t.Cap)
+	}
+	for name, p := range t.Positions {
+		if !validScore(p.Base) || p.Cap < 0 || p.Cap > 10 {
+			return fmt.Errorf("position %q: base and cap must be within [0, 10]", name)
+		}
+		for _, r := range p.Required {
+			if len(r.AnyOf) == 0 {
+				return fmt.Errorf("position %q: required rule %q has empty anyOf", name, r.Name)
+			}
+			if !r.Reject && r.Penalty <= 0 {
+				return fmt.Errorf("position %q: required rule %q needs a positive penalty or reject", name, r.Name)
+			}
+		}
+		for _, r := range p.Bonuses {
+			if len(r.AnyOf) == 0 {
+				return fmt.Errorf("position %q: bonus rule %q has empty anyOf", name, r.Name)
+			}
+			if r.Bonus == 0 {
+				return fmt.Errorf("position %q: bonus rule %q has zero bonus", name, r.Name)
+			}
+		}
+	}
+	in := rs.Intent
+	if !validScore(in.Base) {
+		return fmt.Errorf("intent.base %v out of range [0, 10]", in.Base)
+	}
+	if in.Cap <= 0 || in.Cap > 10 {
+		return fmt.Errorf("intent.cap %v out of range (0, 10]", in.Cap)
+	}
+	if in.SalaryTolerance < 0 {
+		return errors.New("intent.salaryTolerance must not be negative")
+	}
+	for name, band := range in.SalaryBands {
+		if band.Min < 0 || band.Max <= 0 || band.Min > band.Max {
+			return fmt.Errorf("intent salary band %q: invalid range [%d, %d]", name, band.Min, band.Max)
+		}
+	}
+	if j := rs.Jobs; j.RequiredSkillPenalty < 0 || j.PreferredSkillBonus < 0 || j.EducationFloorPenalty < 0 {
+		return errors.New("jobs penalties and bonuses must not be negative")
+	}
+	if m := rs.Jobs.Match; m.RequiredSkills < 0 || m.PreferredSkills < 0 || m.Years < 0 ||
+		m.Education < 0 || m.City < 0 || m.Salary < 0 || m.Sum() == 0 {
+		return errors.New("jobs.match weights must not be negative or all zero")
+	}
+	if err := rs.Weights.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Parse 解析并校验规则集。文件中缺少的分节，或分节中缺少的字段，取内置规则的值，
+// 新增分节之前编写的规则文件因此仍能通过校验
+func Parse(data []byte) (*RuleSet, error) {
+	data, err := withDefaults(data)
+	if err != nil {
+		return nil, err
+	}
+	rs := &RuleSet{}
+	if err := json.Unmarshal(data, rs); err != nil {
+		return nil, err
+	}
+	if err := rs.Validate(); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+//go:embed default_rules.json
+var _default []byte
+
+// withDefaults fills the sections missing from a rule file, and the keys missing from its
+// sections, with those of the built-in rules. Keys below a section such as positions are
+// taken from the file as a whole and never merged.
+func withDefaults(data []byte) ([]byte, error) {
+	var file, defaults map[string]json.RawMessage
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(_default, &defaults); err != nil {
+		return nil, err
+	}
+	if file == nil {
+		file = map[string]json.RawMessage{}
+	}
+	for key, value := range defaults {
+		var defaultSection map[string]json.RawMessage
+		if err := json.Unmarshal(value, &defaultSection); err != nil {
+			continue // version 等非分节字段必须由文件给出
+		}
+		current, ok := file[key]
+		if !ok {
+			file[key] = value
+			continue
+		}
+		var section map[string]json.RawMessage
+		if err := json.Unmarshal(current, &section); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		if section == nil {
+			section = map[string]json.RawMessage{}
+		}
+		for k, v := range defaultSection {
+			if _, ok := section[k]; !ok {
+				section[k] = v
+			}
+		}
+		merged, err := json.Marshal(section)
+		if err != nil {
+			return nil, err
+		}
+		file[key] = merged
+	}
+	return json.Marshal(file)
+}
+
+var (
+	mu     sync.RWMutex
+	active *RuleSet
+)
+
+// init 先使用内置规则，main 读取配置后调用 Reload 加载 SCORING_RULES
+func init() {
+	rs, err := Parse(_default)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default scoring rules: %v", err))
+	}
+	active = rs
+}
+
+// Active 返回当前生效的规则集
+func Active() *RuleSet {
+	mu.RLock()
+	defer mu.RUnlock()
+	return active
+}
+
+// Reload 从 SCORING_RULES 指定的文件重新加载规则，未配置时使用内置规则
+func Reload() (*RuleSet, error) {
+	data := _default
+	if config.SCORING_RULES != "" {
+		var err error
+		data, err = os.ReadFile(config.SCORING_RULES)
+		if err != nil {
+			return nil, err
+		}
+	}
+	rs, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	mu.Lock()
+	active = rs
+	mu.Unlock()
+	return rs, nil
+}
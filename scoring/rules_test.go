@@ -0,0 +1,123 @@
+package scoring
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"talents/config"
+)
+
+func TestReload(t *testing.T) {
+	rules := map[string]any{}
+	if err := json.Unmarshal(_default, &rules); err != nil {
+		t.Fatal(err)
+	}
+	rules["version"] = "custom"
+	data, err := json.Marshal(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		config.SCORING_RULES = ""
+		if _, err := Reload(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	config.SCORING_RULES = path
+	rs, err := Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Version != "custom" || Active() != rs {
+		t.Errorf("active version = %q, want custom", Active().Version)
+	}
+	config.SCORING_RULES = filepath.Join(t.TempDir(), "missing.json")
+	if _, err := Reload(); err == nil || Active() != rs {
+		t.Errorf("reload of a missing file = %v, want an error that keeps the active rules", err)
+	}
+}
+
+func TestParseRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not json", `{`},
+		{"missing version", `{"education": {"cap": 4}, "technical": {"cap": 8}}`},
+		{"education cap out of range", `{"version": "v", "education": {"cap": 11}, "technical": {"cap": 8}}`},
+		{"negative base", `{"version": "v", "experience": {"base": -1}, "education": {"cap": 4}, "technical": {"cap": 8}}`},
+		{"unnamed company tier", `{"version": "v", "experience": {"companyTiers": [{"score": 6}]},
+			"education": {"cap": 4}, "technical": {"cap": 8}}`},
+		{"required skill without penalty", `{"version": "v", "education": {"cap": 4}, "technical": {"cap": 8,
+			"positions": {"后端": {"required": [{"name": "语言", "anyOf": ["go"]}]}}}}`},
+		{"intent cap out of range", `{"version": "v", "intent": {"cap": 11}}`},
+		{"section is not an object", `{"version": "v", "intent": 3}`},
+	}
+	for _, tt := range tests {
+		if _, err := Parse([]byte(tt.data)); err == nil {
+			t.Errorf("%s: Parse succeeded, want an error", tt.name)
+		}
+	}
+	if _, err := Parse(_default); err != nil {
+		t.Errorf("default rules: %v", err)
+	}
+}
+
+func TestParseFillsMissingSections(t *testing.T) {
+	defaults, err := Parse(_default)
+	if err != nil {
+		t.Fatalf("default rules: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		data  string
+		check func(t *testing.T, rs *RuleSet)
+	}{
+		{
+			name: "rule file without intent and weights",
+			data: `{"version": "legacy", "experience": {"base": 2},
+				"education": {"cap": 8, "majorBonus": 1},
+				"technical": {"base": 3, "cap": 9, "positions": {"后端": {"bonuses": [{"name": "go", "anyOf": ["go"], "bonus": 1}]}}}}`,
+			check: func(t *testing.T, rs *RuleSet) {
+				if rs.Version != "legacy" {
+					t.Errorf("version = %q, want legacy", rs.Version)
+				}
+				if rs.Experience.Base != 2 || rs.Education.Cap != 8 || rs.Technical.Cap != 9 {
+					t.Errorf("values from the file were overwritten: %+v", rs)
+				}
+				if rs.Intent.Cap != defaults.Intent.Cap || rs.Weights != defaults.Weights {
+					t.Errorf("missing sections were not filled from the defaults")
+				}
+				if len(rs.Technical.Positions) != 1 {
+					t.Errorf("positions merged with the defaults: %v", rs.Technical.Positions)
+				}
+			},
+		},
+		{
+			name: "values given in the file win",
+			data: `{"version": "custom", "intent": {"cap": 4}}`,
+			check: func(t *testing.T, rs *RuleSet) {
+				if rs.Intent.Cap != 4 {
+					t.Errorf("intent.cap = %v, want 4", rs.Intent.Cap)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs, err := Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			tt.check(t, rs)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package skill
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Skill 技能分类条目：标准名称、同义词以及所属的上级分类
+type Skill struct {
+	Name     string   `json:"name"`
+	Synonyms []string `json:"synonyms"`
+	Parents  []string `json:"parents"`
+}
+
+//go:embed skills.json
+var _str string
+
+var _skills map[string]*Skill    // 标准名称 -> 技能
+var _canonical map[string]string // 标准名称及同义词 -> 标准名称
+
+func init() {
+	skills := []*Skill{}
+	if err := json.Unmarshal([]byte(_str), &skills); err != nil {
+		panic(err)
+	}
+	_skills = make(map[string]*Skill)
+	_canonical = make(map[string]string)
+	for _, s := range skills {
+		name := clean(s.Name)
+		for _, n := range append([]string{s.Name}, s.Synonyms...) {
+			if other, ok := _canonical[clean(n)]; ok {
+				panic(fmt.Sprintf("skill %q is both %q and %q", n, other, name))
+			}
+			_canonical[clean(n)] = name
+		}
+		_skills[name] = s
+	}
+}
+
+func clean(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// All returns the whole taxonomy
+func All() []*Skill {
+	skills := make([]*Skill, 0, len(_skills))
+	for _, s := range _skills {
+		skills = append(skills, s)
+	}
+	return skills
+}
+
+// Canonical returns the canonical name of a skill; unknown skills are only lowercased
+func Canonical(name string) string {
+	name = clean(name)
+	if c, ok := _canonical[name]; ok {
+		return c
+	}
+	return name
+}
+
+// Normalize maps skills to their canonical names, dropping empty entries and duplicates
+func Normalize(skills []string) []string {
+	if skills == nil {
+		return nil
+	}
+	normalized := make([]string, 0, len(skills))
+	seen := make(map[string]bool)
+	for _, s := range skills {
+		c := Canonical(s)
+		if c == "" || seen[c] {
+			continue
+		}
+		seen[c] = true
+		normalized = append(normalized, c)
+	}
+	return normalized
+}
+
+// is reports whether skill is term itself or belongs to term through its parent categories
+func is(skill, term string, depth int) bool {
+	if skill == term {
+		return true
+	}
+	s, ok := _skills[skill]
+	if !ok || depth > 8 {
+		return false
+	}
+	for _, p := range s.Parents {
+		if is(Canonical(p), term, depth+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the first skill that is one of the given terms. Skills and terms are
+// compared by canonical name, never by substring.
+func Match(skills []string, terms ...string) (string, bool) {
+	return match(skills, terms, false)
+}
+
+// MatchChildren is like Match but also accepts skills that belong to a term through their
+// parent categories, e.g. mysql for 数据库
+func MatchChildren(skills []string, terms ...string) (string, bool) {
+	return match(skills, terms, true)
+}
+
+func match(skills []string, terms []string, children bool) (string, bool) {
+	for _, t := range terms {
+		t = Canonical(t)
+		for _, s := range skills {
+			if c := Canonical(s); c == t || children && is(c, t, 0) {
+				return s, true
+			}
+		}
+	}
+	return "", false
+}
+
+// MatchAny reports whether any skill is one of the given terms
+func MatchAny(skills []string, terms ...string) bool {
+	_, ok := Match(skills, terms...)
+	return ok
+}
@@ -0,0 +1,35 @@
+package skill
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		skills   []string
+		terms    []string
+		children bool
+		want     string // empty when nothing should match
+	}{
+		{"canonical name", []string{"Go"}, []string{"go"}, false, "Go"},
+		{"synonym", []string{"golang"}, []string{"go"}, false, "golang"},
+		{"no substring match", []string{"django"}, []string{"go"}, false, ""},
+		{"child without children", []string{"量化"}, []string{"ai"}, false, ""},
+		{"child with children", []string{"量化"}, []string{"ai"}, true, "量化"},
+		{"grandchild with children", []string{"lora"}, []string{"ai"}, true, "lora"},
+		{"parent never matches child", []string{"数据库"}, []string{"mysql"}, true, ""},
+		{"github is not git", []string{"github"}, []string{"git"}, false, ""},
+		{"gitlab is git", []string{"gitlab"}, []string{"git"}, false, "gitlab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := Match
+			if tt.children {
+				match = MatchChildren
+			}
+			got, ok := match(tt.skills, tt.terms...)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("match(%v, %v) = %q, %v, want %q", tt.skills, tt.terms, got, ok, tt.want)
+			}
+		})
+	}
+}
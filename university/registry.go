@@ -0,0 +1,212 @@
+package university
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// BuiltinVersion 内置院校排名数据的版本名
+const BuiltinVersion = "builtin"
+
+// registry 一个版本的院校库
+type registry struct {
+	version string
+	// index 规范化后的中文名、英文名、univUp 及别名到院校的映射
+	index map[string]*University
+	// list 全部院校，国内院校在前
+	list []*University
+	// resolved 缓存名称解析结果，模糊匹配需要遍历整个索引
+	resolved resolveCache
+}
+
+// resolveCacheSize 名称解析缓存的条目上限，写满后清空重新缓存
+const resolveCacheSize = 4096
+
+// resolveCache 名称解析结果的缓存，未匹配的名称缓存为 nil
+type resolveCache struct {
+	mu sync.Mutex
+	m  map[string]*University
+}
+
+func (c *resolveCache) load(key string) (*University, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	u, ok := c.m[key]
+	return u, ok
+}
+
+func (c *resolveCache) store(key string, u *University) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.m == nil || len(c.m) >= resolveCacheSize {
+		c.m = make(map[string]*University)
+	}
+	c.m[key] = u
+}
+
+func (c *resolveCache) clear() {
+	c.mu.Lock()
+	c.m = nil
+	c.mu.Unlock()
+}
+
+var (
+	mu       sync.RWMutex
+	active   *registry
+	_builtin *registry
+)
+
+func init() {
+	universities, err := Parse([]byte(_str))
+	if err != nil {
+		panic(fmt.Sprintf("invalid builtin university data: %v", err))
+	}
+	_builtin, err = build(BuiltinVersion, universities, true)
+	if err != nil {
+		panic(err)
+	}
+	active = _builtin
+}
+
+func current() *registry {
+	mu.RLock()
+	defer mu.RUnlock()
+	return active
+}
+
+// Version 返回当前生效的院校排名数据版本
+func Version() string {
+	return current().version
+}
+
+// Parse 解析并校验国内院校排名数据，格式与内置的 university_total.json 相同
+func Parse(data []byte) ([]University, error) {
+	universities := []University{}
+	if err := json.Unmarshal(data, &universities); err != nil {
+		return nil, err
+	}
+	if len(universities) == 0 {
+		return nil, errors.New("university list is empty")
+	}
+	seen := make(map[string]bool)
+	for i, u := range universities {
+		name := strings.TrimSpace(u.UnivNameCn)
+		if name == "" {
+			return nil, fmt.Errorf("university #%d: univNameCn is required", i+1)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate university %q", name)
+		}
+		seen[name] = true
+		if u.Score < 0 || u.WorldRank < 0 {
+			return nil, fmt.Errorf("university %q: score and worldRank must not be negative", name)
+		}
+		if u.CSRating != "" && !ValidCSRating(u.CSRating) {
+			return nil, fmt.Errorf("university %q: unknown csRating %q", name, u.CSRating)
+		}
+	}
+	return universities, nil
+}
+
+// build indexes the mainland ranking together with the embedded world ranking, tiers and
+// aliases. In strict mode tiers and aliases must all refer to known universities; uploaded
+// rankings may drop universities, so their missing targets are skipped.
+func build(version string, universities []University, strict bool) (*registry, error) {
+	world := []University{}
+	if err := json.Unmarshal(_world, &world); err != nil {
+		return nil, err
+	}
+	aliases := map[string][]string{}
+	if err := json.Unmarshal(_aliases, &aliases); err != nil {
+		return nil, err
+	}
+	t := tiers{}
+	if err := json.Unmarshal(_tiers, &t); err != nil {
+		return nil, err
+	}
+
+	r := &registry{version: version, index: make(map[string]*University)}
+	add := func(name string, u *University) {
+		// 排名靠前的院校优先，不覆盖已有的名称
+		if key := normalize(name); key != "" && r.index[key] == nil {
+			r.index[key] = u
+		}
+	}
+	// 先加入国内院校，境外院校不会覆盖国内院校的名称
+	for _, list := range [][]University{universities, world} {
+		for i := range list {
+			u := &list[i]
+			r.list = append(r.list, u)
+			add(u.UnivNameCn, u)
+			add(u.UnivNameEn, u)
+			add(strings.ReplaceAll(u.UnivUp, "-", " "), u)
+		}
+	}
+	if err := t.apply(r.index, strict); err != nil {
+		return nil, err
+	}
+	for name, list := range aliases {
+		u := r.index[normalize(name)]
+		if u == nil {
+			if strict {
+				return nil, fmt.Errorf("university alias target %q not found", name)
+			}
+			continue
+		}
+		for _, alias := range list {
+			add(alias, u)
+		}
+	}
+	return r, nil
+}
+
+// Ranking 解析好的院校排名数据版本，调用 Activate 后才生效
+type Ranking struct {
+	r *registry
+}
+
+// Load 解析并校验院校排名数据，不改变当前生效的版本
+func Load(version string, data []byte) (*Ranking, error) {
+	universities, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	r, err := build(version, universities, false)
+	if err != nil {
+		return nil, err
+	}
+	return &Ranking{r: r}, nil
+}
+
+// Activate 切换为当前生效的版本
+func (k *Ranking) Activate() {
+	activate(k.r)
+}
+
+// Use 解析院校排名数据并切换为当前生效的版本
+func Use(version string, data []byte) error {
+	k, err := Load(version, data)
+	if err != nil {
+		return err
+	}
+	k.Activate()
+	return nil
+}
+
+// UseBuiltin 切换回内置的院校排名数据
+func UseBuiltin() {
+	activate(_builtin)
+}
+
+// activate switches the active registry and drops the cached names of the previous one
+func activate(r *registry) {
+	mu.Lock()
+	previous := active
+	active = r
+	mu.Unlock()
+	previous.resolved.clear()
+	r.resolved.clear()
+}
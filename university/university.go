@@ -2,31 +2,337 @@ package university
 
 import (
 	_ "embed"
-	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
 )
 
+// 院校层次
+const (
+	Tier985              = "985"
+	Tier211              = "211"
+	TierDoubleFirstClass = "双一流"
+)
+
+// csRatings 计算机科学与技术学科评估等级，由高到低
+var csRatings = []string{"A+", "A", "A-", "B+", "B", "B-", "C+", "C", "C-"}
+
+// University 院校。国内院校按 Score 排名，境外院校按世界排名 WorldRank
 type University struct {
-	UnivUp     string  `json:"univUp"`
-	UnivLogo   string  `json:"univLogo"`
-	UnivNameCn string  `json:"univNameCn"`
-	UnivNameEn string  `json:"univNameEn"`
-	Score      float32 `json:"score"`
+	UnivUp           string  `json:"univUp"`
+	UnivLogo         string  `json:"univLogo"`
+	UnivNameCn       string  `json:"univNameCn"`
+	UnivNameEn       string  `json:"univNameEn"`
+	Score            float32 `json:"score"`
+	Country          string  `json:"country,omitempty"`
+	WorldRank        int     `json:"worldRank,omitempty"`
+	Is985            bool    `json:"is985,omitempty"`
+	Is211            bool    `json:"is211,omitempty"`
+	DoubleFirstClass bool    `json:"doubleFirstClass,omitempty"` // 双一流
+	CSRating         string  `json:"csRating,omitempty"`         // 计算机科学与技术学科评估等级
+}
+
+// tiers 985、211、双一流名单及计算机学科评估等级，键为院校中文全称
+type tiers struct {
+	Is985            []string            `json:"985"`
+	Is211            []string            `json:"211"`
+	DoubleFirstClass []string            `json:"双一流"`
+	CSRatings        map[string][]string `json:"csRatings"`
 }
 
+// _str 内置的国内院校排名，可通过 Use 切换为上传的版本
+//
 //go:embed university_total.json
 var _str string
-var _m map[string]float32
 
-func init() {
-	_m = make(map[string]float32)
-	universities := []University{}
-	err := json.Unmarshal([]byte(_str), &universities)
-	if err != nil {
-		panic(err)
+// _world 境外院校的世界排名快照。与国内院校重名时（中文名、英文名或 univUp 相同）以国内排名为准
+//
+//go:embed university_world.json
+var _world []byte
+
+// _tiers 院校层次与学科评估，与数据中已有的标记合并，数据中已有学科评估等级的院校以数据为准
+//
+//go:embed tiers.json
+var _tiers []byte
+
+// _aliases 常用简称、英文缩写，键为院校中文全称
+//
+//go:embed aliases.json
+var _aliases []byte
+
+// apply sets the tier flags and CS ratings on universities that do not carry them yet.
+// In strict mode a name missing from the index is an error, otherwise it is skipped.
+func (t *tiers) apply(index map[string]*University, strict bool) error {
+	lookup := func(name string) (*University, error) {
+		u := index[normalize(name)]
+		if u == nil && strict {
+			return nil, fmt.Errorf("university tier target %q not found", name)
+		}
+		return u, nil
 	}
-	for _, university := range universities {
-		_m[university.UnivNameCn] = university.Score
+	for _, flag := range []struct {
+		names []string
+		set   func(u *University)
+	}{
+		{t.Is985, func(u *University) { u.Is985 = true }},
+		{t.Is211, func(u *University) { u.Is211 = true }},
+		{t.DoubleFirstClass, func(u *University) { u.DoubleFirstClass = true }},
+	} {
+		for _, name := range flag.names {
+			u, err := lookup(name)
+			if err != nil {
+				return err
+			}
+			if u != nil {
+				flag.set(u)
+			}
+		}
+	}
+	for rating, names := range t.CSRatings {
+		if !ValidCSRating(rating) {
+			return fmt.Errorf("invalid CS rating %q", rating)
+		}
+		for _, name := range names {
+			u, err := lookup(name)
+			if err != nil {
+				return err
+			}
+			if u != nil && u.CSRating == "" {
+				u.CSRating = rating
+			}
+		}
+	}
+	return nil
+}
+
+// ValidTier reports whether tier is 985, 211 or 双一流
+func ValidTier(tier string) bool {
+	return tier == Tier985 || tier == Tier211 || tier == TierDoubleFirstClass
+}
+
+// ValidCSRating reports whether rating is a discipline rating such as A+ or B-
+func ValidCSRating(rating string) bool {
+	return csRatingRank(rating) >= 0
+}
+
+// csRatingRank returns the position of a rating in csRatings, or -1 if unknown
+func csRatingRank(rating string) int {
+	for i, r := range csRatings {
+		if r == rating {
+			return i
+		}
+	}
+	return -1
+}
+
+// HasTier reports whether the university belongs to the given tier. 985 院校都是 211，
+// 211 院校都是双一流
+func (u *University) HasTier(tier string) bool {
+	switch tier {
+	case Tier985:
+		return u.Is985
+	case Tier211:
+		return u.Is211 || u.Is985
+	case TierDoubleFirstClass:
+		return u.DoubleFirstClass || u.Is211 || u.Is985
 	}
+	return false
+}
+
+// CSRatingAtLeast reports whether the university's CS rating is at least floor
+func (u *University) CSRatingAtLeast(floor string) bool {
+	rank := csRatingRank(u.CSRating)
+	return rank >= 0 && rank <= csRatingRank(floor)
+}
+
+// Satisfies reports whether the university meets the tier and minimum CS rating, empty
+// conditions are ignored
+func (u *University) Satisfies(tier string, csRating string) bool {
+	if tier != "" && !u.HasTier(tier) {
+		return false
+	}
+	if csRating != "" && !u.CSRatingAtLeast(csRating) {
+		return false
+	}
+	return true
+}
+
+// Qualifies returns the first of the named universities that meets the tier and minimum CS rating
+func Qualifies(names []string, tier string, csRating string) (*University, bool) {
+	for _, name := range names {
+		if u, ok := Resolve(name); ok && u.Satisfies(tier, csRating) {
+			return u, true
+		}
+	}
+	return nil, false
+}
+
+// Filter 院校查询条件，Query 匹配中英文名称
+type Filter struct {
+	Query    string
+	Tier     string
+	CSRating string
+	Country  string
+}
+
+// List 按条件列出院校，国内院校按排名在前
+func List(f Filter) []*University {
+	query := normalize(f.Query)
+	list := make([]*University, 0)
+	for _, u := range current().list {
+		if query != "" && !strings.Contains(normalize(u.UnivNameCn), query) && !strings.Contains(normalize(u.UnivNameEn), query) {
+			continue
+		}
+		if f.Country != "" && u.Country != f.Country && !(f.Country == "中国" && u.Country == "") {
+			continue
+		}
+		if !u.Satisfies(f.Tier, f.CSRating) {
+			continue
+		}
+		list = append(list, u)
+	}
+	return list
+}
+
+var (
+	spacePattern  = regexp.MustCompile(`\s+`)
+	campusPattern = regexp.MustCompile(`\([^()]*\)$`)
+)
+
+// normalize lowercases a name, unifies full-width brackets and collapses whitespace and punctuation
+func normalize(name string) string {
+	name = strings.NewReplacer("（", "(", "）", ")", "&", " and ", ",", " ", ".", " ", "·", "").Replace(name)
+	name = strings.ToLower(strings.TrimSpace(name))
+	return spacePattern.ReplaceAllString(name, " ")
+}
+
+// stripCampus removes a trailing campus such as (威海) or 宏福校区 from a normalized name
+func stripCampus(name string) string {
+	name = strings.TrimSpace(campusPattern.ReplaceAllString(name, ""))
+	if strings.HasSuffix(name, "校区") || strings.HasSuffix(name, "分校") {
+		if i := max(strings.LastIndex(name, "大学"), strings.LastIndex(name, "学院")); i > 0 {
+			name = name[:i+len("大学")]
+		}
+	}
+	return name
+}
+
+// Resolve 将简历中的院校名称解析为院校库中的院校：依次尝试全称、英文名、别名，
+// 去掉校区后缀后再试，最后做编辑距离的模糊匹配，有歧义时不匹配
+func Resolve(name string) (*University, bool) {
+	key := normalize(name)
+	if key == "" {
+		return nil, false
+	}
+	r := current()
+	if u, ok := r.resolved.load(key); ok {
+		return u, u != nil
+	}
+	u := r.resolve(key)
+	r.resolved.store(key, u)
+	return u, u != nil
+}
+
+func (r *registry) resolve(key string) *University {
+	if u := r.index[key]; u != nil {
+		return u
+	}
+	key = stripCampus(key)
+	if u := r.index[key]; u != nil {
+		return u
+	}
+	if !strings.HasSuffix(key, "大学") && !strings.HasSuffix(key, "学院") {
+		if u := r.index[key+"大学"]; u != nil {
+			return u
+		}
+	}
+	return r.fuzzy(key)
+}
+
+// fuzzy returns the only university within the allowed edit distance of the key.
+// English names of at least 10 letters allow 2 edits. Chinese names of at least 4 characters
+// allow 1 edit after the place name: 西京学院 and 西昌学院 are different schools, so the key
+// must start with the whole text before 大学, 学院 or 学校 of the candidate.
+func (r *registry) fuzzy(key string) *University {
+	n := utf8.RuneCountInString(key)
+	chinese := n != len(key)
+	limit := 0
+	switch {
+	case !chinese && n >= 10:
+		limit = 2
+	case chinese && n >= 4:
+		limit = 1
+	}
+	if limit == 0 {
+		return nil
+	}
+
+	var match *University
+	for name, u := range r.index {
+		if chinese {
+			place := placeName(name)
+			if utf8.RuneCountInString(place) < 2 || !strings.HasPrefix(key, place) {
+				continue
+			}
+		}
+		if distance(key, name, limit) > limit || u == match {
+			continue
+		}
+		if match != nil {
+			// 不止一所院校在编辑距离内
+			return nil
+		}
+		match = u
+	}
+	return match
+}
+
+// placeName returns the text before the last 大学, 学院 or 学校 of a Chinese name, or ""
+// if the name has none of them
+func placeName(name string) string {
+	i := max(strings.LastIndex(name, "大学"), strings.LastIndex(name, "学院"), strings.LastIndex(name, "学校"))
+	if i < 0 {
+		return ""
+	}
+	return name[:i]
+}
+
+// distance returns the Levenshtein distance between a and b, or limit+1 once it exceeds limit
+func distance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if abs(len(ra)-len(rb)) > limit {
+		return limit + 1
+	}
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, cur = cur, prev
+	}
+	return min(prev[len(rb)], limit+1)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
 }
 
 func CalcScore(universities []string) float32 {
@@ -38,7 +344,18 @@ func CalcScore(universities []string) float32 {
 }
 
 func calcScore(name string) float32 {
-	score := _m[name]
+	if u, ok := Resolve(name); ok {
+		return u.Scaled()
+	}
+	return 0
+}
+
+// Scaled 将院校排名换算为 0-8 分
+func (u *University) Scaled() float32 {
+	if u.WorldRank > 0 {
+		return worldRankScore(u.WorldRank)
+	}
+	score := u.Score
 	// Convert the score from 0-1076.1 to 0-8 scale
 	var convertedScore float32
 	switch {
@@ -57,3 +374,30 @@ func calcScore(name string) float32 {
 
 	return convertedScore
 }
+
+// worldRankScore maps a world ranking onto the same 0-8 scale as the mainland ranking:
+// top 10 get 8, rank 100 about 6.5 (a strong 985), rank 500 about 4.5, beyond 1000 get 2
+func worldRankScore(rank int) float32 {
+	r := float32(rank)
+	switch {
+	case rank <= 10:
+		return 8
+	case rank <= 50:
+		// Map linearly from 10-50 to 8-7
+		return 8 - (r-10)/40
+	case rank <= 100:
+		// Map linearly from 50-100 to 7-6.5
+		return 7 - (r-50)*0.5/50
+	case rank <= 200:
+		// Map linearly from 100-200 to 6.5-6
+		return 6.5 - (r-100)*0.5/100
+	case rank <= 500:
+		// Map linearly from 200-500 to 6-4.5
+		return 6 - (r-200)*1.5/300
+	case rank <= 1000:
+		// Map linearly from 500-1000 to 4.5-3
+		return 4.5 - (r-500)*1.5/500
+	default:
+		return 2
+	}
+}
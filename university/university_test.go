@@ -0,0 +1,148 @@
+package university
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name string
+		want string // 中文全称，为空表示不应匹配
+	}{
+		// 全称、英文名与别名
+		{"清华大学", "清华大学"},
+		{"Tsinghua University", "清华大学"},
+		{"tsinghua  university", "清华大学"},
+		{"北大", "北京大学"},
+		{"清华", "清华大学"},
+		// 校区
+		{"山东大学（威海）", "山东大学"},
+		{"北京大学(医学部)", "北京大学"},
+		{"北京师范大学珠海校区", "北京师范大学"},
+		// 模糊匹配
+		{"浙江大家", "浙江大学"},
+		{"Peking Universty", "北京大学"},
+		{"Harvard Universty", "哈佛大学"},
+		// 相近但不同的院校
+		{"东北大学", "东北大学"},
+		{"东南大学", "东南大学"},
+		// 不应匹配
+		{"", ""},
+		{"北京大", ""},
+		{"某某大学", ""},
+		{"中国大学", ""},
+		{"Zhejiang Univ", ""},
+		// 地名有错字时不做模糊匹配，以免匹配到另一所院校
+		{"清化大学", ""},
+		{"西京学院", ""},
+		{"三亚学院", ""},
+		{"文华学院", ""},
+		{"广州商学院", ""},
+		{"福建大学", ""},
+		{"重庆学院", ""},
+		{"吉林学院", ""},
+		{"东南学院", ""},
+		{"THU", ""},
+		// 有歧义的缩写：NTU 也是台湾大学，ETH 泛指瑞士联邦理工，USC、UCB 各有多所院校
+		{"NTU", ""},
+		{"ETH", ""},
+		{"USC", ""},
+		{"UCB", ""},
+		{"National Taiwan University", "台湾大学"},
+		{"NTU Singapore", "南洋理工大学"},
+		{"ETHZ", "苏黎世联邦理工学院"},
+		{"南加大", "南加州大学"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, ok := Resolve(tt.name)
+			got := ""
+			if ok {
+				got = u.UnivNameCn
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"浙江大学", "浙江大学", 1, 0},
+		{"浙江大家", "浙江大学", 1, 1},
+		{"浙江", "浙江大学", 1, 2},
+		{"kitten", "sitting", 3, 3},
+		{"kitten", "sitting", 2, 3},
+	}
+	for _, tt := range tests {
+		if got := distance(tt.a, tt.b, tt.limit); got != tt.want {
+			t.Errorf("distance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestResolveCache(t *testing.T) {
+	r := current()
+	for i := range resolveCacheSize + 10 {
+		Resolve(fmt.Sprintf("院校%d", i))
+	}
+	if n := len(r.resolved.m); n > resolveCacheSize {
+		t.Errorf("cached names = %d, want at most %d", n, resolveCacheSize)
+	}
+	if _, ok := Resolve("清华大学"); !ok {
+		t.Fatal("清华大学 not resolved")
+	}
+	UseBuiltin()
+	if n := len(r.resolved.m); n != 0 {
+		t.Errorf("cached names after switching = %d, want 0", n)
+	}
+}
+
+func TestTiers(t *testing.T) {
+	tests := []struct {
+		name     string
+		tier     string
+		csRating string
+		want     bool
+	}{
+		{"清华大学", Tier985, "A+", true},
+		{"北京邮电大学", Tier985, "", false},
+		{"北京邮电大学", Tier211, "A-", true},
+		{"北京邮电大学", TierDoubleFirstClass, "A", false},
+		{"南方科技大学", TierDoubleFirstClass, "", true},
+		{"南方科技大学", Tier211, "", false},
+		{"深圳大学", "", "B", true},
+		{"深圳大学", "", "B+", false},
+		{"深圳大学", TierDoubleFirstClass, "", false},
+	}
+	for _, tt := range tests {
+		u, ok := Resolve(tt.name)
+		if !ok {
+			t.Fatalf("%s not resolved", tt.name)
+		}
+		if got := u.Satisfies(tt.tier, tt.csRating); got != tt.want {
+			t.Errorf("%s Satisfies(%q, %q) = %v, want %v", tt.name, tt.tier, tt.csRating, got, tt.want)
+		}
+	}
+
+	if u, ok := Qualifies([]string{"某某学院", "北邮", "清华大学"}, Tier211, "B+"); !ok || u.UnivNameCn != "北京邮电大学" {
+		t.Errorf("Qualifies = %v, %v, want 北京邮电大学", u, ok)
+	}
+	if _, ok := Qualifies([]string{"深圳大学"}, Tier211, ""); ok {
+		t.Error("深圳大学 qualifies as 211")
+	}
+
+	var names []string
+	for _, u := range List(Filter{Tier: Tier985, CSRating: "A+"}) {
+		names = append(names, u.UnivNameCn)
+	}
+	if len(names) != 3 {
+		t.Errorf("985 with A+ = %v, want 北京大学, 清华大学 and 浙江大学", names)
+	}
+}